@@ -45,6 +45,11 @@ func (a *Apache) GetLatestRelease() (Release, error) {
 	if err != nil {
 		return Release{}, err
 	}
+
+	// skip pre-releases and directories which are not versions, e.g., latest
+	ars = slices.DeleteFunc(ars, func(ar ApacheRelease) bool {
+		return !isVersion(ar.Name) || isPreRelease(ar.Name)
+	})
 	if len(ars) == 0 {
 		return Release{}, ErrNoRelease
 	}
@@ -76,6 +81,20 @@ func (a *Apache) GetTaggedRelease(tag string) (Release, error) {
 	return a.getRelease(ar)
 }
 
+func (a *Apache) ListReleases() ([]Release, error) {
+	ars, err := a.getReleases()
+	if err != nil {
+		return nil, err
+	}
+
+	// assets are fetched by GetTaggedRelease
+	var releases []Release
+	for _, ar := range ars {
+		releases = append(releases, a.convertRelease(ar))
+	}
+	return releases, nil
+}
+
 func (a *Apache) getReleases() ([]ApacheRelease, error) {
 	baseURL := a.url
 	links, err := getLinks(baseURL)
@@ -133,8 +152,9 @@ func (a *Apache) getRelease(ar ApacheRelease) (Release, error) {
 
 func (a *Apache) convertRelease(ar ApacheRelease) Release {
 	r := Release{
-		Name:    ar.Name,
-		TagName: ar.TagName,
+		Name:       ar.Name,
+		TagName:    ar.TagName,
+		Prerelease: isVersion(ar.Name) && isPreRelease(ar.Name),
 	}
 	for _, aa := range ar.Assets {
 		r.Assets = append(r.Assets, *NewAsset(aa.Name, aa.URL))
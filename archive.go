@@ -0,0 +1,276 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	compressionNone  = ""
+	compressionGzip  = "gzip"
+	compressionXz    = "xz"
+	compressionBzip2 = "bzip2"
+	compressionZstd  = "zstd"
+)
+
+// tarball suffixes and their compressions
+var tarSuffixes = []struct {
+	suffix      string
+	compression string
+}{
+	{".tar.gz", compressionGzip},
+	{".tgz", compressionGzip},
+	{".tar.xz", compressionXz},
+	{".txz", compressionXz},
+	{".tar.bz2", compressionBzip2},
+	{".tbz2", compressionBzip2},
+	{".tar.zst", compressionZstd},
+	{".tzst", compressionZstd},
+	{".tar", compressionNone},
+}
+
+// single file compression suffixes
+var compressedSuffixes = []struct {
+	suffix      string
+	compression string
+}{
+	{".gz", compressionGzip},
+	{".xz", compressionXz},
+	{".bz2", compressionBzip2},
+	{".zst", compressionZstd},
+}
+
+var compressionMagics = []struct {
+	magic       []byte
+	compression string
+}{
+	{[]byte{0x1f, 0x8b}, compressionGzip},
+	{[]byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, compressionXz},
+	{[]byte("BZh"), compressionBzip2},
+	{[]byte{0x28, 0xb5, 0x2f, 0xfd}, compressionZstd},
+}
+
+// walkFunc is called with the regular executable files in the archive.
+type walkFunc func(name string, r io.Reader, mode os.FileMode) error
+
+// isSupportedArchiveFormat reports whether the name is of a tarball or zip.
+func isSupportedArchiveFormat(name string) bool {
+	_, ok := tarCompression(name)
+	return ok || strings.HasSuffix(strings.ToLower(name), ".zip")
+}
+
+// tarCompression returns the compression of the tarball by its name.
+func tarCompression(name string) (string, bool) {
+	name = strings.ToLower(name)
+	for _, v := range tarSuffixes {
+		if strings.HasSuffix(name, v.suffix) {
+			return v.compression, true
+		}
+	}
+	return "", false
+}
+
+// newDecompressor returns the reader of the decompressed r.
+func newDecompressor(r io.Reader, compression string) (io.ReadCloser, error) {
+	switch compression {
+	case compressionNone:
+		return io.NopCloser(r), nil
+	case compressionGzip:
+		return gzip.NewReader(r)
+	case compressionXz:
+		xr, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(xr), nil
+	case compressionBzip2:
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	case compressionZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression: %s", compression)
+	}
+}
+
+// archiveStream closes both the decompressor and the file.
+type archiveStream struct {
+	io.ReadCloser
+	file *os.File
+}
+
+func (s archiveStream) Close() error {
+	s.ReadCloser.Close()
+	return s.file.Close()
+}
+
+// openArchiveStream opens the decompressed stream of the file.
+func openArchiveStream(path, compression string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := newDecompressor(file, compression)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return archiveStream{ReadCloser: rc, file: file}, nil
+}
+
+// decompressFile decompresses the single file src to dst.
+func decompressFile(src, dst, compression string) error {
+	stream, err := openArchiveStream(src, compression)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	file, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, stream); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// binaryName derives the binary name from the asset name by stripping the compression suffix,
+// and everything from the first platform or version token, e.g., foo from foo_1.0.0_linux_amd64.gz.
+func binaryName(assetName, fallback string) string {
+	name := assetName
+	for _, v := range compressedSuffixes {
+		if strings.HasSuffix(strings.ToLower(name), v.suffix) {
+			name = name[:len(name)-len(v.suffix)]
+			break
+		}
+	}
+
+	end := 0
+	for start := 0; start < len(name); {
+		i := strings.IndexAny(name[start:], tokenSeparators)
+		if i == -1 {
+			i = len(name) - start
+		}
+		if token := strings.ToLower(name[start : start+i]); isPlatformToken(token) {
+			break
+		}
+		end = start + i
+		start += i + 1
+	}
+
+	if name = strings.TrimRight(name[:end], tokenSeparators); name == "" {
+		return fallback
+	}
+	return name
+}
+
+// isPlatformToken reports whether the token is a version, OS, arch or libc, e.g., v1, linux64, x86, gnu.
+func isPlatformToken(token string) bool {
+	if token == "" {
+		return false
+	}
+	if token[0] >= '0' && token[0] <= '9' || (token[0] == 'v' && len(token) > 1 && token[1] >= '0' && token[1] <= '9') {
+		return true
+	}
+	switch token {
+	case "gnu", "musl", "static", "unknown", "pc", "apple":
+		return true
+	}
+	for _, t := range []string{token, strings.TrimRight(token, "0123456789")} {
+		if containsOS(t) || containsArch(t) || findARMVariant(t) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// walkArchive calls fn with the regular executable files in the tarball or zip,
+// or the binaries in the deb, rpm or apk package, the format is the sniffed content and compression.
+func walkArchive(archivePath, content, compression string, fn walkFunc) error {
+	switch content {
+	case contentAr:
+		return walkPackage(archivePath, packageDeb, fn)
+	case contentRPM:
+		return walkPackage(archivePath, packageRPM, fn)
+	case contentZip:
+		return walkZip(archivePath, fn)
+	case contentTar:
+		// apk is the concatenated gzip tarballs
+		if compression == compressionGzip && packageFormat(archivePath) == packageAPK {
+			return walkPackage(archivePath, packageAPK, fn)
+		}
+
+		stream, err := openArchiveStream(archivePath, compression)
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+
+		return walkTar(stream, fn)
+	default:
+		return fmt.Errorf("Unsupported archive file: %s", filepath.Base(archivePath))
+	}
+}
+
+func walkTar(r io.Reader, fn walkFunc) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// Check if it is regulare executable file
+		if header.Typeflag == tar.TypeReg && header.Mode&0111 != 0 {
+			if err := fn(header.Name, tr, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func walkZip(archivePath string, fn walkFunc) error {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, file := range r.File {
+		if fh := file.FileHeader; !fh.FileInfo().IsDir() && fh.Mode()&0111 != 0 {
+			rc, err := file.Open()
+			if err != nil {
+				return err
+			}
+			err = fn(fh.Name, rc, os.FileMode(fh.Mode()))
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+func writeTestTar(t *testing.T, w io.Writer) {
+	t.Helper()
+	tw := tar.NewWriter(w)
+	files := []struct {
+		name string
+		mode int64
+	}{
+		{"example/README.md", 0644},
+		{"example/example", 0755},
+	}
+	for _, f := range files {
+		body := []byte("content of " + f.name)
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: f.mode, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWalkArchive(t *testing.T) {
+	tests := []struct {
+		name       string
+		compressor func(io.Writer) (io.WriteCloser, error)
+	}{
+		{"example.tar.gz", func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil }},
+		{"example.txz", func(w io.Writer) (io.WriteCloser, error) { return xz.NewWriter(w) }},
+		{"example.tar.zst", func(w io.Writer) (io.WriteCloser, error) { return zstd.NewWriter(w) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.name)
+			f, err := os.Create(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w, err := tt.compressor(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeTestTar(t, w)
+			w.Close()
+			f.Close()
+
+			content, compression, err := sniffContent(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			err = walkArchive(path, content, compression, func(name string, r io.Reader, mode os.FileMode) error {
+				names = append(names, name)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("walkArchive() error = %v", err)
+			}
+			if want := []string{"example/example"}; !slices.Equal(names, want) {
+				t.Errorf("walkArchive() = %v, want %v", names, want)
+			}
+		})
+	}
+}
+
+func TestBinaryName(t *testing.T) {
+	tests := []struct {
+		assetName string
+		expected  string
+	}{
+		{"yq_linux_amd64.gz", "yq"},
+		{"tool-linux-amd64.xz", "tool"},
+		{"foo_1.0.0_linux_amd64.zst", "foo"},
+		{"site24x7_exporter-1.1.1-aarch64-unknown-linux-gnu.bz2", "site24x7_exporter"},
+		{"jq-linux64.gz", "jq"},
+		{"linux-amd64.gz", "repo"},
+	}
+
+	for _, test := range tests {
+		if result := binaryName(test.assetName, "repo"); result != test.expected {
+			t.Errorf("binaryName(%s) = %s; expected %s", test.assetName, result, test.expected)
+		}
+	}
+}
+
+func TestIsSupportedArchiveFormat(t *testing.T) {
+	tests := map[string]bool{
+		"tool_linux_amd64.tar.gz":  true,
+		"tool_linux_amd64.TZST":    true,
+		"tool_windows_amd64.zip":   true,
+		"tool_linux_amd64.gz":      false,
+		"tool_1.0.0_amd64.deb":     false,
+		"tool_linux_amd64.tar.asc": false,
+	}
+	for name, want := range tests {
+		if got := isSupportedArchiveFormat(name); got != want {
+			t.Errorf("isSupportedArchiveFormat(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
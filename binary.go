@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var ErrIncompatibleBinary = errors.New("incompatible binary")
+
+// ELF machine, class and byte order of the GOARCH
+var elfArchs = map[string]struct {
+	machine elf.Machine
+	class   elf.Class
+	data    elf.Data
+}{
+	"386":      {elf.EM_386, elf.ELFCLASS32, elf.ELFDATA2LSB},
+	"amd64":    {elf.EM_X86_64, elf.ELFCLASS64, elf.ELFDATA2LSB},
+	"arm":      {elf.EM_ARM, elf.ELFCLASS32, elf.ELFDATA2LSB},
+	"arm64":    {elf.EM_AARCH64, elf.ELFCLASS64, elf.ELFDATA2LSB},
+	"loong64":  {elf.EM_LOONGARCH, elf.ELFCLASS64, elf.ELFDATA2LSB},
+	"mips":     {elf.EM_MIPS, elf.ELFCLASS32, elf.ELFDATA2MSB},
+	"mipsle":   {elf.EM_MIPS, elf.ELFCLASS32, elf.ELFDATA2LSB},
+	"mips64":   {elf.EM_MIPS, elf.ELFCLASS64, elf.ELFDATA2MSB},
+	"mips64le": {elf.EM_MIPS, elf.ELFCLASS64, elf.ELFDATA2LSB},
+	"ppc64":    {elf.EM_PPC64, elf.ELFCLASS64, elf.ELFDATA2MSB},
+	"ppc64le":  {elf.EM_PPC64, elf.ELFCLASS64, elf.ELFDATA2LSB},
+	"riscv64":  {elf.EM_RISCV, elf.ELFCLASS64, elf.ELFDATA2LSB},
+	"s390x":    {elf.EM_S390, elf.ELFCLASS64, elf.ELFDATA2MSB},
+}
+
+var machoCpus = map[string]macho.Cpu{
+	"386":   macho.Cpu386,
+	"amd64": macho.CpuAmd64,
+	"arm":   macho.CpuArm,
+	"arm64": macho.CpuArm64,
+}
+
+// validateBinary checks the ELF or Mach-O binary is built for the target, and the interpreter and glibc
+// versions of the dynamic ELF binary are satisfied by the host, other files, e.g., scripts, are not checked.
+func validateBinary(path string, p Platform) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	magic, err := readFull(file, 4)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(path)
+	goos := p.OS
+	goarch, _ := parseArch(p.Arch)
+	switch magicContent(magic) {
+	case contentELF:
+		if goos == "darwin" || goos == "windows" {
+			return fmt.Errorf("%w: %s is an ELF binary, not for %s", ErrIncompatibleBinary, name, goos)
+		}
+		f, err := elf.NewFile(file)
+		if err != nil {
+			return fmt.Errorf("invalid ELF binary %s: %w", name, err)
+		}
+		if err := checkELFArch(f, goarch); err != nil {
+			return fmt.Errorf("%w: %s %v", ErrIncompatibleBinary, name, err)
+		}
+		// the interpreter and glibc are only checked for the host, including its libc
+		if p.isHost() {
+			if err := checkInterpreter(f, name); err != nil {
+				return err
+			}
+			return checkGlibcVersion(f, name)
+		}
+	case contentMachO:
+		if goos != "darwin" {
+			return fmt.Errorf("%w: %s is a Mach-O binary, not for %s", ErrIncompatibleBinary, name, goos)
+		}
+		cpus, err := machoCPUs(file)
+		if err != nil {
+			return fmt.Errorf("invalid Mach-O binary %s: %w", name, err)
+		}
+		if cpu, ok := machoCpus[goarch]; ok && !slices.Contains(cpus, cpu) {
+			return fmt.Errorf("%w: %s is built for %v, not %s", ErrIncompatibleBinary, name, cpus, goarch)
+		}
+	}
+	return nil
+}
+
+func checkELFArch(f *elf.File, goarch string) error {
+	want, ok := elfArchs[goarch]
+	if !ok {
+		return nil
+	}
+	switch {
+	case f.Machine != want.machine:
+		return fmt.Errorf("is built for %s, not %s", strings.TrimPrefix(f.Machine.String(), "EM_"), goarch)
+	case f.Class != want.class:
+		return fmt.Errorf("is %s, not %s of %s", f.Class, want.class, goarch)
+	case f.Data != want.data:
+		return fmt.Errorf("is %s, not %s of %s", f.Data, want.data, goarch)
+	}
+	return nil
+}
+
+// checkInterpreter checks the interpreter of the dynamic binary, e.g., /lib/ld-musl-x86_64.so.1 is missing on glibc hosts.
+func checkInterpreter(f *elf.File, name string) error {
+	interp, err := elfInterpreter(f)
+	if err != nil || interp == "" {
+		return err
+	}
+	if _, err := os.Stat(interp); err == nil {
+		return nil
+	}
+
+	libc := "the"
+	switch interpreterLibc(interp) {
+	case libcMusl:
+		libc = "musl"
+	case libcGNU:
+		libc = "glibc"
+	}
+	return fmt.Errorf("%w: %s requires %s interpreter %s, which is not found", ErrIncompatibleBinary, name, libc, interp)
+}
+
+// elfInterpreter returns the PT_INTERP of the binary, or empty for the static binary.
+func elfInterpreter(f *elf.File) (string, error) {
+	for _, prog := range f.Progs {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+		data, err := io.ReadAll(prog.Open())
+		if err != nil {
+			return "", err
+		}
+		return string(bytes.TrimRight(data, "\x00")), nil
+	}
+	return "", nil
+}
+
+// machoCPUs returns the CPUs of the Mach-O or universal binary.
+func machoCPUs(r io.ReaderAt) ([]macho.Cpu, error) {
+	if fat, err := macho.NewFatFile(r); err == nil {
+		cpus := make([]macho.Cpu, len(fat.Arches))
+		for i, arch := range fat.Arches {
+			cpus[i] = arch.Cpu
+		}
+		return cpus, nil
+	}
+
+	f, err := macho.NewFile(r)
+	if err != nil {
+		return nil, err
+	}
+	return []macho.Cpu{f.Cpu}, nil
+}
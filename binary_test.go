@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// testELF returns a minimal 64-bit ELF binary, with the interpreter if not empty.
+func testELF(machine elf.Machine, data elf.Data, interp string) []byte {
+	var order binary.ByteOrder = binary.LittleEndian
+	if data == elf.ELFDATA2MSB {
+		order = binary.BigEndian
+	}
+
+	header := elf.Header64{
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(machine),
+		Version:   uint32(elf.EV_CURRENT),
+		Ehsize:    64,
+		Phentsize: 56,
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(data)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	if interp != "" {
+		header.Phoff = 64
+		header.Phnum = 1
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, order, header)
+	if interp != "" {
+		binary.Write(&buf, order, elf.Prog64{
+			Type:   uint32(elf.PT_INTERP),
+			Off:    120,
+			Filesz: uint64(len(interp) + 1),
+			Memsz:  uint64(len(interp) + 1),
+		})
+		buf.WriteString(interp + "\x00")
+	}
+	return buf.Bytes()
+}
+
+func testMachO(cpu macho.Cpu) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, macho.FileHeader{
+		Magic: macho.Magic64,
+		Cpu:   cpu,
+		Type:  macho.TypeExec,
+	})
+	// reserved
+	buf.Write(make([]byte, 4))
+	return buf.Bytes()
+}
+
+func TestValidateBinary(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		goos    string
+		goarch  string
+		wantErr error
+	}{
+		{"amd64", testELF(elf.EM_X86_64, elf.ELFDATA2LSB, ""), "linux", "amd64", nil},
+		{"arm64 for amd64", testELF(elf.EM_AARCH64, elf.ELFDATA2LSB, ""), "linux", "amd64", ErrIncompatibleBinary},
+		{"64-bit for arm", testELF(elf.EM_ARM, elf.ELFDATA2LSB, ""), "linux", "armv7", ErrIncompatibleBinary},
+		{"big endian for ppc64le", testELF(elf.EM_PPC64, elf.ELFDATA2MSB, ""), "linux", "ppc64le", ErrIncompatibleBinary},
+		{"ELF for darwin", testELF(elf.EM_AARCH64, elf.ELFDATA2LSB, ""), "darwin", "arm64", ErrIncompatibleBinary},
+		{"cross target interpreter", testELF(elf.EM_AARCH64, elf.ELFDATA2LSB, "/lib/ld-nonexistent.so.1"), "linux", "arm64", nil},
+		{"Mach-O arm64", testMachO(macho.CpuArm64), "darwin", "arm64", nil},
+		{"Mach-O arm64 for amd64", testMachO(macho.CpuArm64), "darwin", "amd64", ErrIncompatibleBinary},
+		{"Mach-O for linux", testMachO(macho.CpuAmd64), "linux", "amd64", ErrIncompatibleBinary},
+		{"script", []byte("#!/bin/sh\necho hello\n"), "darwin", "arm64", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "example")
+			if err := os.WriteFile(path, tt.data, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := validateBinary(path, Platform{OS: tt.goos, Arch: tt.goarch}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateBinary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateBinaryInterpreter(t *testing.T) {
+	arch, ok := elfArchs[runtime.GOARCH]
+	if !ok || arch.class != elf.ELFCLASS64 || runtime.GOOS != "linux" {
+		t.Skip("not a 64-bit linux host")
+	}
+
+	path := filepath.Join(t.TempDir(), "example")
+	if err := os.WriteFile(path, testELF(arch.machine, arch.data, "/lib/ld-nonexistent.so.1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateBinary(path, hostPlatform()); !errors.Is(err, ErrIncompatibleBinary) {
+		t.Errorf("validateBinary() error = %v, wantErr %v", err, ErrIncompatibleBinary)
+	}
+
+	// the interpreter of another libc is not on the host, e.g., -libc musl on a glibc host
+	other := hostPlatform()
+	other.Libc = libcMusl
+	if other.Libc == hostLibc() {
+		other.Libc = libcGNU
+	}
+	if err := validateBinary(path, other); err != nil {
+		t.Errorf("validateBinary() for %s error = %v", other, err)
+	}
+
+	// the interpreter of the test binary exists
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateBinary(exe, hostPlatform()); err != nil {
+		t.Errorf("validateBinary(%s) error = %v", exe, err)
+	}
+}
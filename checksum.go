@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+var (
+	// SHA256 (file) = digest
+	bsdChecksumRe = regexp.MustCompile(`^(MD5|SHA1|SHA224|SHA256|SHA384|SHA512) ?\((.+)\) ?= ?([0-9a-fA-F]+)$`)
+	// digest  file, digest *file
+	gnuChecksumRe = regexp.MustCompile(`^([0-9a-fA-F]+) [ *]?(.+)$`)
+	hexDigestRe   = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+)
+
+// hex digest length to algorithm
+var checksumAlgoByLen = map[int]string{
+	32:  "md5",
+	40:  "sha1",
+	56:  "sha224",
+	64:  "sha256",
+	96:  "sha384",
+	128: "sha512",
+}
+
+type Checksum struct {
+	Algo   string
+	Digest string
+}
+
+func (c Checksum) NewHash() hash.Hash {
+	switch c.Algo {
+	case "md5":
+		return md5.New()
+	case "sha1":
+		return sha1.New()
+	case "sha224":
+		return sha256.New224()
+	case "sha256":
+		return sha256.New()
+	case "sha384":
+		return sha512.New384()
+	case "sha512":
+		return sha512.New()
+	}
+	return nil
+}
+
+func (c Checksum) Verify(h hash.Hash) error {
+	if got := hex.EncodeToString(h.Sum(nil)); got != c.Digest {
+		return fmt.Errorf("%w: expected %s %s, got %s", ErrChecksumMismatch, c.Algo, c.Digest, got)
+	}
+	return nil
+}
+
+// parseChecksum looks up the checksum of the named file in the content of a checksum file.
+// GNU coreutils, BSD style and single hash files are supported.
+func parseChecksum(data []byte, name string) (Checksum, bool) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+
+	for _, line := range lines {
+		if m := bsdChecksumRe.FindStringSubmatch(line); m != nil {
+			if isSameFileName(m[2], name) {
+				return newChecksum(strings.ToLower(m[1]), m[3])
+			}
+		} else if m := gnuChecksumRe.FindStringSubmatch(line); m != nil {
+			if isSameFileName(m[2], name) {
+				return newChecksum("", m[1])
+			}
+		}
+	}
+
+	// single hash file, e.g., foo.tar.gz.sha256
+	if len(lines) == 1 {
+		if fields := strings.Fields(lines[0]); hexDigestRe.MatchString(fields[0]) && (len(fields) == 1 || isSameFileName(fields[len(fields)-1], name)) {
+			return newChecksum("", fields[0])
+		}
+	}
+
+	return Checksum{}, false
+}
+
+func newChecksum(algo, digest string) (Checksum, bool) {
+	digest = strings.ToLower(digest)
+	byLen, ok := checksumAlgoByLen[len(digest)]
+	if !ok || (algo != "" && algo != byLen) {
+		return Checksum{}, false
+	}
+	return Checksum{Algo: byLen, Digest: digest}, true
+}
+
+func isSameFileName(s, name string) bool {
+	return path.Base(strings.TrimSpace(s)) == name
+}
+
+func isHashFile(name string) bool {
+	return hashFileRe.MatchString(strings.ToLower(name))
+}
+
+// findChecksumAssets returns the checksum assets which may contain the checksum of the asset,
+// the ones dedicated to the asset come first, e.g., foo.tar.gz.sha256 before checksums.txt.
+func findChecksumAssets(assets []Asset, asset Asset) Assets {
+	var dedicated, shared Assets
+	for _, a := range assets {
+		if !isHashFile(a.Name) {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(a.Name), strings.ToLower(asset.Name)+".") {
+			dedicated = append(dedicated, a)
+			continue
+		}
+		isOthers := false
+		for _, other := range assets {
+			if other.Name != a.Name && !isHashFile(other.Name) && strings.HasPrefix(strings.ToLower(a.Name), strings.ToLower(other.Name)+".") {
+				isOthers = true
+				break
+			}
+		}
+		if !isOthers {
+			shared = append(shared, a)
+		}
+	}
+	return append(dedicated, shared...)
+}
+
+// fetchChecksum downloads the checksum assets of the release until the checksum of the asset is found,
+// and returns the checksum asset, which is downloaded to destDir.
+func fetchChecksum(release Release, asset Asset, destDir string) (Checksum, Asset, bool, error) {
+	for _, a := range findChecksumAssets(release.Assets, asset) {
+		destPath := filepath.Join(destDir, a.Name)
+		if err := download(a.URL, destPath, release.AuthHeaders, nil); err != nil {
+			return Checksum{}, Asset{}, false, err
+		}
+		data, err := os.ReadFile(destPath)
+		if err != nil {
+			return Checksum{}, Asset{}, false, err
+		}
+		if c, ok := parseChecksum(data, asset.Name); ok {
+			log.Printf("Found %s checksum of %s in %s", c.Algo, asset.Name, a.Name)
+			return c, a, true, nil
+		}
+	}
+	return Checksum{}, Asset{}, false, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseChecksum(t *testing.T) {
+	const (
+		sha256Digest = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+		md5Digest    = "5eb63bbbe01eeed093cb22bb8f5acdc3"
+	)
+
+	tests := []struct {
+		name     string
+		data     string
+		filename string
+		want     Checksum
+		found    bool
+	}{
+		{
+			name:     "gnu",
+			data:     "0000000000000000000000000000000000000000000000000000000000000000  example_linux_arm64.tar.gz\n" + sha256Digest + "  example_linux_amd64.tar.gz\n",
+			filename: "example_linux_amd64.tar.gz",
+			want:     Checksum{Algo: "sha256", Digest: sha256Digest},
+			found:    true,
+		},
+		{
+			name:     "gnu binary mode",
+			data:     sha256Digest + " *dist/example_linux_amd64.tar.gz\n",
+			filename: "example_linux_amd64.tar.gz",
+			want:     Checksum{Algo: "sha256", Digest: sha256Digest},
+			found:    true,
+		},
+		{
+			name:     "bsd",
+			data:     "SHA256 (example_linux_amd64.tar.gz) = " + sha256Digest + "\n",
+			filename: "example_linux_amd64.tar.gz",
+			want:     Checksum{Algo: "sha256", Digest: sha256Digest},
+			found:    true,
+		},
+		{
+			name:     "bsd algorithm mismatch",
+			data:     "SHA512 (example_linux_amd64.tar.gz) = " + sha256Digest + "\n",
+			filename: "example_linux_amd64.tar.gz",
+			found:    false,
+		},
+		{
+			name:     "single hash",
+			data:     md5Digest + "\n",
+			filename: "example_linux_amd64.tar.gz",
+			want:     Checksum{Algo: "md5", Digest: md5Digest},
+			found:    true,
+		},
+		{
+			name:     "not listed",
+			data:     sha256Digest + "  example_linux_arm64.tar.gz\n",
+			filename: "example_linux_amd64.tar.gz",
+			found:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := parseChecksum([]byte(tt.data), tt.filename)
+			if found != tt.found || got != tt.want {
+				t.Errorf("parseChecksum() = %v, %v, want %v, %v", got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestFindChecksumAssets(t *testing.T) {
+	assets := []Asset{
+		{Name: "example_linux_amd64.tar.gz"},
+		{Name: "example_linux_arm64.tar.gz"},
+		{Name: "example_linux_arm64.tar.gz.sha256"},
+		{Name: "checksums.txt"},
+		{Name: "example_linux_amd64.tar.gz.sha256"},
+	}
+
+	got := findChecksumAssets(assets, assets[0]).JoinName()
+	want := "example_linux_amd64.tar.gz.sha256, checksums.txt"
+	if got != want {
+		t.Errorf("findChecksumAssets() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// commands are run as `release-installer <command> [args]`, anything else is a repo to install.
+var commands = map[string]func(args []string) error{
+	"explain":   runExplain,
+	"list":      runList,
+	"outdated":  runOutdated,
+	"sync":      runSync,
+	"uninstall": runUninstall,
+	"upgrade":   runUpgrade,
+}
+
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print in JSON")
+	fs.Parse(args)
+
+	s, err := LoadState()
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		installs := s.Installs
+		if installs == nil {
+			installs = []InstallRecord{}
+		}
+		return enc.Encode(installs)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPO\tTAG\tPROVIDER\tASSET\tFILES\tINSTALLED")
+	for _, r := range s.Installs {
+		tag := r.Tag
+		if r.Prerelease {
+			tag += " (pre-release)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Repo, tag, r.Provider, r.Asset, strings.Join(r.Files, ","), r.InstalledAt.Format("2006-01-02 15:04:05"))
+	}
+	return w.Flush()
+}
+
+func runUninstall(args []string) error {
+	fs := flag.NewFlagSet("uninstall", flag.ExitOnError)
+	dir := fs.String("dir", "", "only uninstall from the installation directory")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return errors.New("missing repo")
+	}
+	repo := fs.Arg(0)
+
+	if *dir != "" {
+		absDir, err := filepath.Abs(*dir)
+		if err != nil {
+			return err
+		}
+		*dir = absDir
+	}
+
+	s, err := LoadState()
+	if err != nil {
+		return err
+	}
+
+	records := s.Find(repo, *dir)
+	if len(records) == 0 {
+		return fmt.Errorf("%s is not installed", repo)
+	}
+
+	for _, r := range records {
+		for _, f := range r.Files {
+			if err := os.Remove(f); err != nil {
+				if !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+				log.Printf("%s does not exist", f)
+				continue
+			}
+			log.Printf("Removed %s", f)
+		}
+		s.Remove(r)
+		if err := s.Save(); err != nil {
+			return err
+		}
+		log.Printf("Uninstalled %s %s from %s", r.Repo, r.Tag, r.Dir)
+	}
+
+	return nil
+}
+
+// latestTag returns the latest tag of the installed package, within the recorded version constraint.
+func latestTag(r InstallRecord, token string) (string, error) {
+	g, err := r.options(token).repoProvider()
+	if err != nil {
+		return "", err
+	}
+	release, err := resolveRelease(g, r.options(token))
+	if err != nil {
+		return "", err
+	}
+	return release.TagName, nil
+}
+
+// selectRecords returns the installs of the repos, or all installs if no repo is given.
+// The token is only sent to the named repos, others use the token of the recorded token_env,
+// so that a token is never sent to the host of another install.
+func selectRecords(repos []string, token string) ([]InstallRecord, error) {
+	if token != "" && len(repos) == 0 {
+		return nil, errors.New("-token requires the repos to use it for")
+	}
+
+	s, err := LoadState()
+	if err != nil {
+		return nil, err
+	}
+	if len(repos) == 0 {
+		return s.Installs, nil
+	}
+
+	var records []InstallRecord
+	for _, repo := range repos {
+		found := s.Find(repo, "")
+		if len(found) == 0 {
+			return nil, fmt.Errorf("%s is not installed", repo)
+		}
+		records = append(records, found...)
+	}
+	return records, nil
+}
+
+func runOutdated(args []string) error {
+	fs := flag.NewFlagSet("outdated", flag.ExitOnError)
+	token := fs.String("token", "", "token for the given private repos")
+	fs.Parse(args)
+
+	records, err := selectRecords(fs.Args(), *token)
+	if err != nil {
+		return err
+	}
+
+	var outdated, failed int
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPO\tDIR\tCURRENT\tLATEST")
+	for _, r := range records {
+		tag, err := latestTag(r, *token)
+		if err != nil {
+			log.Printf("Error checking %s: %v", r.Repo, err)
+			failed++
+			continue
+		}
+		if tag != r.Tag {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Repo, r.Dir, r.Tag, tag)
+			outdated++
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to check %d packages", failed)
+	}
+	if outdated > 0 {
+		return exitError(1)
+	}
+	return nil
+}
+
+func runUpgrade(args []string) error {
+	fs := flag.NewFlagSet("upgrade", flag.ExitOnError)
+	token := fs.String("token", "", "token for the given private repos")
+	fs.Parse(args)
+
+	records, err := selectRecords(fs.Args(), *token)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, r := range records {
+		tag, err := latestTag(r, *token)
+		if err != nil {
+			log.Printf("Error checking %s: %v", r.Repo, err)
+			failed++
+			continue
+		}
+		if tag == r.Tag {
+			log.Printf("%s %s is up to date", r.Repo, r.Tag)
+			continue
+		}
+
+		log.Printf("Upgrading %s from %s to %s", r.Repo, r.Tag, tag)
+		// the tag is resolved by the constraint, which is kept for the next upgrade
+		opts := r.options(*token)
+		opts.Tag, opts.Constraint = tag, ""
+		record, err := install(opts)
+		if err != nil {
+			log.Printf("Error upgrading %s: %v", r.Repo, err)
+			failed++
+			continue
+		}
+		if r.Constraint != "" {
+			record.Constraint = r.Constraint
+			if err := recordInstall(*record); err != nil {
+				log.Printf("Error saving install state: %v", err)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to upgrade %d packages", failed)
+	}
+	return nil
+}
+
+func runSync(args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	file := fs.String("f", "", "manifest file")
+	fs.Parse(args)
+
+	if *file == "" {
+		return errors.New("missing manifest file, use -f")
+	}
+
+	m, err := LoadManifest(*file)
+	if err != nil {
+		return err
+	}
+
+	type result struct {
+		repo   string
+		status string
+		detail string
+	}
+	var (
+		results []result
+		failed  int
+	)
+	for _, e := range m.Tools {
+		log.Printf("Syncing %s", e.Repo)
+		record, err := install(m.options(e))
+		if err != nil {
+			log.Printf("Error installing %s: %v", e.Repo, err)
+			results = append(results, result{e.Repo, "failed", err.Error()})
+			failed++
+			continue
+		}
+		results = append(results, result{e.Repo, "ok", record.Tag})
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPO\tSTATUS\tDETAIL")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.repo, r.status, r.detail)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return exitError(1)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// newGiteaServer serves the releases of owner/tool, the first is the latest, each has a script asset.
+// Requests without the token, or with another token, are refused.
+func newGiteaServer(t *testing.T, token string, tags ...string) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	release := func(tag string) GiteaRelease {
+		name := "tool_linux_amd64"
+		return GiteaRelease{TagName: tag, Assets: []GiteaAsset{{Name: name, BrowserDownloadURL: server.URL + "/download/" + tag + "/" + name}}}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/repos/owner/tool/releases", func(w http.ResponseWriter, r *http.Request) {
+		var releases []GiteaRelease
+		if r.URL.Query().Get("page") == "1" {
+			for _, tag := range tags {
+				releases = append(releases, release(tag))
+			}
+		}
+		json.NewEncoder(w).Encode(releases)
+	})
+	mux.HandleFunc("/api/v1/repos/owner/tool/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(release(tags[0]))
+	})
+	mux.HandleFunc("/api/v1/repos/owner/tool/releases/tags/{tag}", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(release(r.PathValue("tag")))
+	})
+	mux.HandleFunc("/download/{tag}/{name}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("#!/bin/sh\necho " + r.PathValue("tag") + "\n"))
+	})
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); (token == "" && auth != "") || (token != "" && auth != "token "+token) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestUpgradeConstrainedInstall(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	server := newGiteaServer(t, "", "v2.0.0", "v1.3.0", "v1.2.0")
+	dir := t.TempDir()
+
+	o := installOptions{packageOptions: packageOptions{Provider: "gitea", URL: server.URL, Repo: "owner/tool", Dir: dir}, Tag: "v1.2.0"}
+	record, err := install(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the install of -constraint ^1.2
+	record.Constraint = "^1.2"
+	if err := recordInstall(*record); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runUpgrade([]string{"owner/tool"}); err != nil {
+		t.Fatalf("runUpgrade() error = %v", err)
+	}
+	s, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := s.Find("owner/tool", dir)
+	if len(records) != 1 || records[0].Tag != "v1.3.0" || records[0].Constraint != "^1.2" {
+		t.Errorf("upgraded records = %+v, want v1.3.0 constrained by ^1.2", records)
+	}
+	if files := records[0].Files; len(files) != 1 || files[0] != filepath.Join(dir, "tool") {
+		t.Errorf("upgraded files = %v, want %s", files, filepath.Join(dir, "tool"))
+	}
+}
+
+// installTools installs owner/tool of the tag from both the private and the public servers.
+func installTools(t *testing.T, private, public *httptest.Server, tag string) (privateDir, publicDir string) {
+	t.Helper()
+	privateDir, publicDir = t.TempDir(), t.TempDir()
+	for _, o := range []installOptions{
+		{packageOptions: packageOptions{Provider: "gitea", URL: private.URL, TokenEnv: "TEST_GITEA_TOKEN", Repo: "owner/tool", Dir: privateDir}, Token: "secret", Tag: tag},
+		{packageOptions: packageOptions{Provider: "gitea", URL: public.URL, Repo: "owner/tool", Dir: publicDir}, Tag: tag},
+	} {
+		if _, err := install(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return privateDir, publicDir
+}
+
+func TestOutdated(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("TEST_GITEA_TOKEN", "secret")
+	private := newGiteaServer(t, "secret", "v1.1.0", "v1.0.0")
+	public := newGiteaServer(t, "", "v1.0.0")
+	installTools(t, private, public, "v1.0.0")
+
+	// the recorded token is only sent to the private server
+	var exit exitError
+	if err := runOutdated(nil); !errors.As(err, &exit) || exit != 1 {
+		t.Errorf("runOutdated() error = %v, want exit status 1", err)
+	}
+	if err := runOutdated([]string{"-token", "secret"}); err == nil {
+		t.Error("runOutdated(-token) without repos succeeded, want error")
+	}
+	// the token is sent to both servers of the named repo, the public one refuses it
+	if err := runOutdated([]string{"-token", "secret", "owner/tool"}); err == nil || errors.As(err, &exit) {
+		t.Errorf("runOutdated(-token owner/tool) error = %v, want failed check", err)
+	}
+	if err := runOutdated([]string{"owner/other"}); err == nil {
+		t.Error("runOutdated(owner/other) succeeded, want not installed error")
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("TEST_GITEA_TOKEN", "secret")
+	private := newGiteaServer(t, "secret", "v1.1.0", "v1.0.0")
+	public := newGiteaServer(t, "", "v1.1.0", "v1.0.0")
+	privateDir, publicDir := installTools(t, private, public, "v1.0.0")
+
+	if err := runUpgrade([]string{"-token", "secret"}); err == nil {
+		t.Error("runUpgrade(-token) without repos succeeded, want error")
+	}
+	if err := runUpgrade(nil); err != nil {
+		t.Fatalf("runUpgrade() error = %v", err)
+	}
+
+	s, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{privateDir, publicDir} {
+		if records := s.Find("owner/tool", dir); len(records) != 1 || records[0].Tag != "v1.1.0" {
+			t.Errorf("records in %s = %+v, want v1.1.0", dir, records)
+		}
+	}
+}
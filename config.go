@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const configFileName = "config.yaml"
+
+// Config is the user configuration, e.g.,
+//
+//	rules:
+//	  - match: '\.zip$'
+//	    weight: -1
+//	  - token: static
+//	    weight: 1
+//	aliases:
+//	  amd64: x86_64
+//	cosign:
+//	  trusted_root: ~/.config/release-installer/trusted_root.json
+//	gpg:
+//	  keyring: ~/.config/release-installer/pubring.gpg
+//	  fingerprints:
+//	    owner/repo: [0123456789ABCDEF0123456789ABCDEF01234567]
+//	minisign:
+//	  keys:
+//	    owner/repo: RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3
+//	slsa:
+//	  builder_id: https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml
+type Config struct {
+	// scoring rules applied on top of the default ones
+	Rules []ScoringRule `yaml:"rules"`
+	// aliases of the platform values in asset templates, e.g., amd64: x86_64
+	Aliases map[string]string `yaml:"aliases"`
+	// cosign verification, overridden by the flags
+	Cosign CosignConfig `yaml:"cosign"`
+	// gpg verification, and the allowed fingerprints of the repos
+	GPG GPGConfig `yaml:"gpg"`
+	// minisign public keys of the repos
+	Minisign MinisignConfig `yaml:"minisign"`
+	// expected builder of the SLSA provenance, the trusted root is of cosign
+	SLSA SLSAConfig `yaml:"slsa"`
+}
+
+type CosignConfig struct {
+	// public key, e.g., cosign.pub
+	Key string `yaml:"key"`
+	// Sigstore trusted root of keyless signatures, e.g., trusted_root.json
+	TrustedRoot string `yaml:"trusted_root"`
+}
+
+type GPGConfig struct {
+	// keyring of the trusted keys, e.g., KEYS of Apache projects
+	Keyring string `yaml:"keyring"`
+	// fingerprints of the signing keys by repo
+	Fingerprints map[string][]string `yaml:"fingerprints"`
+}
+
+type MinisignConfig struct {
+	// public key, or the file of it, by repo
+	Keys map[string]string `yaml:"keys"`
+}
+
+type SLSAConfig struct {
+	// builder ID without the version
+	BuilderID string `yaml:"builder_id"`
+}
+
+// configPath returns $XDG_CONFIG_HOME/release-installer/config.yaml, defaults to ~/.config/release-installer/config.yaml.
+func configPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "release-installer", configFileName), nil
+}
+
+// LoadConfig loads the config of the path, or the default path if empty, which may not exist.
+func LoadConfig(path string) (*Config, error) {
+	optional := path == ""
+	if optional {
+		var err error
+		if path, err = configPath(); err != nil {
+			return &Config{}, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if optional && errors.Is(err, fs.ErrNotExist) {
+			return &Config{}, nil
+		}
+		return nil, err
+	}
+
+	var c Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	// the paths of the config are not expanded by a shell
+	c.Cosign.Key = expandHome(c.Cosign.Key)
+	c.Cosign.TrustedRoot = expandHome(c.Cosign.TrustedRoot)
+	c.GPG.Keyring = expandHome(c.GPG.Keyring)
+	for repo, key := range c.Minisign.Keys {
+		c.Minisign.Keys[repo] = expandHome(key)
+	}
+	return &c, nil
+}
+
+// expandHome expands the leading ~/ of the path to the home directory.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	constraintOpRe = regexp.MustCompile(`(>=|<=|!=|>|<|=|\^|~)\s+`)
+	partialRe      = regexp.MustCompile(`^[vV]?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?$`)
+)
+
+// Constraint is a version constraint, e.g., ^1.2, ~1.2.3, 1.x, >=2.3, <3, 1.2 - 1.4,
+// comparators separated by comma or space are ANDed, and || separates alternatives.
+type Constraint struct {
+	raw    string
+	groups [][]comparator
+}
+
+type comparator struct {
+	op      string
+	version string
+}
+
+func (c comparator) check(v string) bool {
+	n := compareVersions(v, c.version)
+	switch c.op {
+	case ">":
+		return n > 0
+	case ">=":
+		return n >= 0
+	case "<":
+		return n < 0
+	case "<=":
+		return n <= 0
+	case "!=":
+		return n != 0
+	default:
+		return n == 0
+	}
+}
+
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{raw: s}
+	for _, group := range strings.Split(s, "||") {
+		group = constraintOpRe.ReplaceAllString(group, "$1")
+		fields := strings.FieldsFunc(group, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+
+		var comparators []comparator
+		for i := 0; i < len(fields); i++ {
+			// hyphen range, e.g., 1.2 - 1.4
+			if i+2 < len(fields) && fields[i+1] == "-" {
+				lower, err := parseComparators(">=" + fields[i])
+				if err != nil {
+					return nil, err
+				}
+				upper, err := parseComparators("<=" + fields[i+2])
+				if err != nil {
+					return nil, err
+				}
+				comparators = append(comparators, lower...)
+				comparators = append(comparators, upper...)
+				i += 2
+				continue
+			}
+
+			cs, err := parseComparators(fields[i])
+			if err != nil {
+				return nil, err
+			}
+			comparators = append(comparators, cs...)
+		}
+		c.groups = append(c.groups, comparators)
+	}
+
+	return c, nil
+}
+
+// parseComparators expands the term into comparators of full versions, e.g., ^1.2 is >=1.2.0 <2.0.0.
+func parseComparators(term string) ([]comparator, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", "!=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(term, prefix) {
+			op = prefix
+			term = term[len(prefix):]
+			break
+		}
+	}
+
+	if term == "*" || term == "x" || term == "X" {
+		return nil, nil
+	}
+
+	m := partialRe.FindStringSubmatch(term)
+	if m == nil {
+		// not a plain version, e.g., 1.2.3-rc.1
+		if op == "" || op == "^" || op == "~" {
+			op = "="
+		}
+		// only the v prefix is dropped, pre-release identifiers are case-sensitive
+		if strings.HasPrefix(term, "v") || strings.HasPrefix(term, "V") {
+			term = term[1:]
+		}
+		return []comparator{{op, term}}, nil
+	}
+
+	// numbers before the first wildcard or missing part
+	var nums []int
+	for _, part := range m[1:] {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		nums = append(nums, n)
+	}
+	if len(nums) == 0 {
+		return nil, nil
+	}
+
+	lower := formatVersion(nums)
+	switch op {
+	case "^":
+		// bump the first non-zero part
+		i := 0
+		for i < len(nums)-1 && nums[i] == 0 {
+			i++
+		}
+		return []comparator{{">=", lower}, {"<", bumpVersion(nums, i)}}, nil
+	case "~":
+		// bump the minor if given, or the major
+		return []comparator{{">=", lower}, {"<", bumpVersion(nums, min(len(nums)-1, 1))}}, nil
+	}
+
+	if len(nums) == 3 {
+		if op == "" {
+			op = "="
+		}
+		return []comparator{{op, lower}}, nil
+	}
+
+	// partial version, e.g., 1.2 is 1.2.x
+	upper := bumpVersion(nums, len(nums)-1)
+	switch op {
+	case ">":
+		return []comparator{{">=", upper}}, nil
+	case ">=":
+		return []comparator{{">=", lower}}, nil
+	case "<":
+		return []comparator{{"<", lower}}, nil
+	case "<=":
+		return []comparator{{"<", upper}}, nil
+	case "!=":
+		return nil, fmt.Errorf("invalid constraint: !=%s", term)
+	default:
+		return []comparator{{">=", lower}, {"<", upper}}, nil
+	}
+}
+
+// bumpVersion increases the i-th part of the version, and drops the following parts.
+func bumpVersion(nums []int, i int) string {
+	bumped := append([]int{}, nums[:i+1]...)
+	bumped[i]++
+	return formatVersion(bumped)
+}
+
+// formatVersion formats the parts as a full version, missing parts are 0.
+func formatVersion(nums []int) string {
+	parts := []string{"0", "0", "0"}
+	for i, n := range nums {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ".")
+}
+
+// Check reports whether the version satisfies the constraint.
+func (c *Constraint) Check(v string) bool {
+	for _, group := range c.groups {
+		ok := true
+		for _, cmp := range group {
+			if !cmp.check(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Constraint) String() string {
+	return c.raw
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{"^1.2", "v1.2.0", true},
+		{"^1.2", "1.9.3", true},
+		{"^1.2", "2.0.0", false},
+		{"^1.2", "1.1.9", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"~1", "1.9.0", true},
+		{"1.x", "1.10.1", true},
+		{"1.x", "2.0.0", false},
+		{"1.2.*", "1.2.7", true},
+		{"1.2.*", "1.3.0", false},
+		{"*", "3.0.0", true},
+		{">=2.3, <3", "2.3.0", true},
+		{">=2.3, <3", "2.10.0", true},
+		{">=2.3, <3", "3.0.0", false},
+		{">= 2.3 < 3", "2.2.9", false},
+		{"<=1.2", "1.2.9", true},
+		{">1.2", "1.2.9", false},
+		{">1.2", "1.3.0", true},
+		{"1.2 - 1.4", "1.4.5", true},
+		{"1.2 - 1.4", "1.5.0", false},
+		{"^1 || ^3", "3.1.0", true},
+		{"^1 || ^3", "2.1.0", false},
+		{"1.2.3", "v1.2.3", true},
+		{"!=1.2.3", "1.2.3", false},
+		{"=1.0.0-RC.1", "v1.0.0-RC.1", true},
+		{"V1.0.0-RC.1", "1.0.0-RC.1", true},
+		{"=1.0.0-RC.1", "1.0.0-rc.1", false},
+	}
+
+	for _, test := range tests {
+		c, err := ParseConstraint(test.constraint)
+		if err != nil {
+			t.Errorf("ParseConstraint(%s) error = %v", test.constraint, err)
+			continue
+		}
+		if result := c.Check(test.version); result != test.expected {
+			t.Errorf("Constraint(%s).Check(%s) = %v; expected %v", test.constraint, test.version, result, test.expected)
+		}
+	}
+}
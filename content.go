@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+const (
+	contentELF    = "ELF"
+	contentMachO  = "Mach-O"
+	contentPE     = "PE"
+	contentScript = "script"
+	contentTar    = "tar"
+	contentZip    = "zip"
+	contentAr     = "ar"
+	contentRPM    = "rpm"
+)
+
+var ErrUnsupportedContent = errors.New("unsupported content")
+
+var contentMagics = []struct {
+	magic   []byte
+	content string
+}{
+	{[]byte{0x7f, 'E', 'L', 'F'}, contentELF},
+	{[]byte{0xfe, 0xed, 0xfa, 0xce}, contentMachO},
+	{[]byte{0xfe, 0xed, 0xfa, 0xcf}, contentMachO},
+	{[]byte{0xce, 0xfa, 0xed, 0xfe}, contentMachO},
+	{[]byte{0xcf, 0xfa, 0xed, 0xfe}, contentMachO},
+	// universal binary
+	{[]byte{0xca, 0xfe, 0xba, 0xbe}, contentMachO},
+	{[]byte("MZ"), contentPE},
+	{[]byte("#!"), contentScript},
+	{[]byte("PK\x03\x04"), contentZip},
+	{[]byte("!<arch>\n"), contentAr},
+	{rpmLeadMagic, contentRPM},
+}
+
+// sniffLen is enough for the ustar magic at offset 257 of the tar header.
+const sniffLen = 512
+
+// magicCompression returns the compression by the magic bytes.
+func magicCompression(magic []byte) string {
+	for _, v := range compressionMagics {
+		if bytes.HasPrefix(magic, v.magic) {
+			return v.compression
+		}
+	}
+	return compressionNone
+}
+
+// magicContent returns the content type by the magic bytes, or empty if unknown.
+func magicContent(magic []byte) string {
+	for _, v := range contentMagics {
+		if bytes.HasPrefix(magic, v.magic) {
+			return v.content
+		}
+	}
+	if len(magic) >= 262 && string(magic[257:262]) == "ustar" {
+		return contentTar
+	}
+	return ""
+}
+
+// sniffContent detects the content type of the file by its magic bytes instead of the name,
+// a compressed file is sniffed after decompression, e.g., tar and gzip for a tarball.
+func sniffContent(path string) (content, compression string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer file.Close()
+
+	magic, err := readFull(file, sniffLen)
+	if err != nil {
+		return "", "", err
+	}
+
+	if compression = magicCompression(magic); compression != compressionNone {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return "", "", err
+		}
+		rc, err := newDecompressor(file, compression)
+		if err != nil {
+			return "", "", fmt.Errorf("error decompressing %s: %w", filepath.Base(path), err)
+		}
+		defer rc.Close()
+		if magic, err = readFull(rc, sniffLen); err != nil {
+			return "", "", fmt.Errorf("error decompressing %s: %w", filepath.Base(path), err)
+		}
+	}
+
+	content = magicContent(magic)
+	// packages and zip are not compressed as a whole
+	if content == "" || (compression != compressionNone && content != contentTar && !isExecutableContent(content)) {
+		return "", "", fmt.Errorf("%w of %s: %s", ErrUnsupportedContent, filepath.Base(path), http.DetectContentType(magic))
+	}
+	return content, compression, nil
+}
+
+// readFull reads up to n bytes, a shorter file is not an error.
+func readFull(r io.Reader, n int) ([]byte, error) {
+	buf := make([]byte, n)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// isExecutableContent reports whether the content is installed as is, rather than extracted.
+func isExecutableContent(content string) bool {
+	switch content {
+	case contentELF, contentMachO, contentPE, contentScript:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSniffContent(t *testing.T) {
+	gzipped := func(write func(io.Writer)) []byte {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		write(gw)
+		gw.Close()
+		return buf.Bytes()
+	}
+	elf := []byte("\x7fELF\x02\x01\x01\x00")
+
+	var tarball, zipped bytes.Buffer
+	writeTestTar(t, &tarball)
+	zw := zip.NewWriter(&zipped)
+	zw.Create("example")
+	zw.Close()
+
+	tests := []struct {
+		name            string
+		data            []byte
+		wantContent     string
+		wantCompression string
+		wantErr         error
+	}{
+		{"tool_linux_amd64", elf, contentELF, compressionNone, nil},
+		{"tool_darwin_arm64", []byte{0xcf, 0xfa, 0xed, 0xfe, 0x0c, 0x00, 0x00, 0x01}, contentMachO, compressionNone, nil},
+		{"tool_windows_amd64.exe", []byte("MZ\x90\x00"), contentPE, compressionNone, nil},
+		{"install.sh", []byte("#!/bin/sh\necho hello\n"), contentScript, compressionNone, nil},
+		{"tool_linux_amd64.tar", tarball.Bytes(), contentTar, compressionNone, nil},
+		{"tool_linux_amd64", gzipped(func(w io.Writer) { writeTestTar(t, w) }), contentTar, compressionGzip, nil},
+		{"tool_linux_amd64.gz", gzipped(func(w io.Writer) { w.Write(elf) }), contentELF, compressionGzip, nil},
+		{"tool_linux_amd64.zip", zipped.Bytes(), contentZip, compressionNone, nil},
+		{"tool_linux_amd64.zip", []byte("<!DOCTYPE html><html><body>Not Found</body></html>"), "", "", ErrUnsupportedContent},
+		{"tool_linux_amd64.gz", gzipped(func(w io.Writer) { io.WriteString(w, "plain text") }), "", "", ErrUnsupportedContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.name)
+			if err := os.WriteFile(path, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			content, compression, err := sniffContent(path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("sniffContent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if content != tt.wantContent || compression != tt.wantCompression {
+				t.Errorf("sniffContent() = %q, %q, want %q, %q", content, compression, tt.wantContent, tt.wantCompression)
+			}
+		})
+	}
+}
@@ -0,0 +1,677 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// signature assets of cosign, the bundles come first as they carry the certificate and the tlog entry
+var (
+	cosignBundleSuffixes    = []string{".sigstore.json", ".sigstore", ".bundle"}
+	cosignSignatureSuffixes = []string{".sig"}
+	cosignCertSuffixes      = []string{".pem", ".crt", ".cert"}
+)
+
+// OIDC issuer extensions of Fulcio certificates, https://github.com/sigstore/fulcio/blob/main/docs/oid-info.md
+var (
+	oidIssuer   = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// cosignVerifier verifies the signatures of `cosign sign-blob` offline, by the public key,
+// or by the Fulcio certificate and the Rekor entry trusted by the trusted root for keyless signatures.
+type cosignVerifier struct {
+	key  crypto.PublicKey
+	root *trustedRoot
+	// identity and OIDC issuer of the keyless signing certificate
+	identity *regexp.Regexp
+	issuer   string
+}
+
+// cosignSignature is the signature of an asset, with the signing certificate and the tlog entry if any.
+type cosignSignature struct {
+	sig []byte
+	// SHA-256 digest of the asset in the bundle
+	digest []byte
+	cert   *x509.Certificate
+	chain  []*x509.Certificate
+	tlog   *tlogEntry
+}
+
+// tlogEntry is the Rekor entry of the signature, the signed entry timestamp is the inclusion promise of the log.
+type tlogEntry struct {
+	body           []byte
+	integratedTime int64
+	logIndex       int64
+	logID          []byte
+	set            []byte
+}
+
+func (o installOptions) cosignVerifier(config *Config) (*cosignVerifier, error) {
+	keyPath := cmp.Or(o.CosignKey, config.Cosign.Key)
+	rootPath := cmp.Or(o.TrustedRoot, config.Cosign.TrustedRoot)
+	if keyPath == "" && rootPath == "" {
+		return nil, errors.New("cosign verification requires -cosign-key or -trusted-root")
+	}
+
+	v := &cosignVerifier{issuer: o.CertIssuer}
+	var err error
+	if keyPath != "" {
+		if v.key, err = loadPublicKey(keyPath); err != nil {
+			return nil, err
+		}
+	}
+	if rootPath != "" {
+		if v.root, err = loadTrustedRoot(rootPath); err != nil {
+			return nil, err
+		}
+	}
+	if o.CertIdentity != "" {
+		if v.identity, err = regexp.Compile(o.CertIdentity); err != nil {
+			return nil, fmt.Errorf("invalid certificate identity: %w", err)
+		}
+	}
+	// anyone can get a certificate from Fulcio
+	if v.key == nil && v.identity == nil {
+		return nil, errors.New("keyless cosign verification requires -certificate-identity-regexp")
+	}
+	return v, nil
+}
+
+func (v *cosignVerifier) name() string {
+	return verifyCosign
+}
+
+func (v *cosignVerifier) verify(release Release, target Asset, path, destDir string) (*Verification, error) {
+	var (
+		s   *cosignSignature
+		sig Asset
+	)
+	if a, ok := findSignatureAsset(release.Assets, target.Name, cosignBundleSuffixes...); ok {
+		data, err := downloadSignature(release, a, destDir)
+		if err != nil {
+			return nil, err
+		}
+		if s, err = parseCosignBundle(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", a.Name, err)
+		}
+		sig = a
+	} else if a, ok := findSignatureAsset(release.Assets, target.Name, cosignSignatureSuffixes...); ok {
+		data, err := downloadSignature(release, a, destDir)
+		if err != nil {
+			return nil, err
+		}
+		// the .sig of gpg, the checksum file may have the cosign signature
+		if isOpenPGPSignature(data) {
+			log.Printf("%s is an OpenPGP signature, not of cosign", a.Name)
+			return nil, nil
+		}
+		s = &cosignSignature{sig: decodeBase64OrRaw(data)}
+		if a, ok := findSignatureAsset(release.Assets, target.Name, cosignCertSuffixes...); ok {
+			data, err := downloadSignature(release, a, destDir)
+			if err != nil {
+				return nil, err
+			}
+			certs, err := parseCertificates(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", a.Name, err)
+			}
+			s.cert, s.chain = certs[0], certs[1:]
+		}
+		sig = a
+	} else {
+		return nil, nil
+	}
+
+	if err := v.verifySignature(s, path); err != nil {
+		return nil, err
+	}
+	result := &Verification{Signature: sig.Name}
+	if v.key == nil {
+		result.Signer = strings.Join(certificateIdentities(s.cert), ", ")
+	}
+	return result, nil
+}
+
+// verifySignature verifies the signature of the file, by the public key, or by the certificate trusted at the time of the tlog entry.
+func (v *cosignVerifier) verifySignature(s *cosignSignature, path string) error {
+	digest, err := fileDigest(path, crypto.SHA256)
+	if err != nil {
+		return err
+	}
+	if s.digest != nil && !bytes.Equal(s.digest, digest) {
+		return fmt.Errorf("%w: digest mismatch, expected %x, got %x", ErrInvalidSignature, s.digest, digest)
+	}
+
+	if s.tlog != nil && v.root != nil {
+		if err := v.root.verifyTlogEntry(s.tlog); err != nil {
+			return err
+		}
+		if err := s.tlog.checkBody(s.sig, digest); err != nil {
+			return err
+		}
+	}
+
+	key := v.key
+	if key == nil {
+		if s.cert == nil {
+			return fmt.Errorf("%w: no certificate of the keyless signature", ErrInvalidSignature)
+		}
+		// the certificate is short-lived, and trusted at the time the signature is logged
+		if s.tlog == nil {
+			return fmt.Errorf("%w: no tlog entry of the keyless signature, a bundle is required", ErrInvalidSignature)
+		}
+		if err := v.root.verifyCertificate(s.cert, s.chain, time.Unix(s.tlog.integratedTime, 0)); err != nil {
+			return err
+		}
+		if err := checkCertificateIdentity(s.cert, v.identity, v.issuer); err != nil {
+			return err
+		}
+		key = s.cert.PublicKey
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return verifyBlob(key, f, s.sig)
+}
+
+// checkCertificateIdentity checks the subject alternative names of the certificate if identity is not nil, and the OIDC issuer if any.
+func checkCertificateIdentity(cert *x509.Certificate, identity *regexp.Regexp, issuer string) error {
+	if identities := certificateIdentities(cert); identity != nil && !slices.ContainsFunc(identities, identity.MatchString) {
+		return fmt.Errorf("%w: certificate identity %s not matched by %s", ErrInvalidSignature, strings.Join(identities, ", "), identity)
+	}
+	if got := certificateIssuer(cert); issuer != "" && got != issuer {
+		return fmt.Errorf("%w: certificate OIDC issuer %s, expected %s", ErrInvalidSignature, got, issuer)
+	}
+	return nil
+}
+
+// certificateIdentities returns the emails and URIs of the subject alternative names.
+func certificateIdentities(cert *x509.Certificate) []string {
+	identities := slices.Clone(cert.EmailAddresses)
+	for _, u := range cert.URIs {
+		identities = append(identities, u.String())
+	}
+	return identities
+}
+
+func certificateIssuer(cert *x509.Certificate) string {
+	if issuer := certificateExtension(cert, oidIssuerV2); issuer != "" {
+		return issuer
+	}
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidIssuer) {
+			return string(ext.Value)
+		}
+	}
+	return ""
+}
+
+// certificateExtension returns the DER string of the extension, e.g., the OIDC issuer.
+func certificateExtension(cert *x509.Certificate, oid asn1.ObjectIdentifier) string {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oid) {
+			var s string
+			if _, err := asn1.Unmarshal(ext.Value, &s); err == nil {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// checkBody checks the hashedrekord entry is of the signature and the digest.
+func (e *tlogEntry) checkBody(sig, digest []byte) error {
+	var body struct {
+		Kind string `json:"kind"`
+		Spec struct {
+			Data struct {
+				Hash struct {
+					Algorithm string `json:"algorithm"`
+					Value     string `json:"value"`
+				} `json:"hash"`
+			} `json:"data"`
+			Signature struct {
+				Content []byte `json:"content"`
+			} `json:"signature"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(e.body, &body); err != nil {
+		return fmt.Errorf("invalid tlog entry: %w", err)
+	}
+	if body.Kind != "hashedrekord" {
+		return fmt.Errorf("unsupported tlog entry: %s", body.Kind)
+	}
+	if hash := body.Spec.Data.Hash; hash.Algorithm != "sha256" || hash.Value != hex.EncodeToString(digest) {
+		return fmt.Errorf("%w: tlog entry of %s %s, expected sha256 %x", ErrInvalidSignature, hash.Algorithm, hash.Value, digest)
+	}
+	if !bytes.Equal(body.Spec.Signature.Content, sig) {
+		return fmt.Errorf("%w: tlog entry of another signature", ErrInvalidSignature)
+	}
+	return nil
+}
+
+// sigstoreBundle is the Sigstore bundle, e.g., foo.tar.gz.sigstore.json of `cosign sign-blob --new-bundle-format`.
+type sigstoreBundle struct {
+	MediaType            string `json:"mediaType"`
+	VerificationMaterial struct {
+		Certificate *struct {
+			RawBytes []byte `json:"rawBytes"`
+		} `json:"certificate"`
+		X509CertificateChain *struct {
+			Certificates []struct {
+				RawBytes []byte `json:"rawBytes"`
+			} `json:"certificates"`
+		} `json:"x509CertificateChain"`
+		TlogEntries []struct {
+			LogIndex int64 `json:"logIndex,string"`
+			LogID    struct {
+				KeyID []byte `json:"keyId"`
+			} `json:"logId"`
+			IntegratedTime   int64 `json:"integratedTime,string"`
+			InclusionPromise *struct {
+				SignedEntryTimestamp []byte `json:"signedEntryTimestamp"`
+			} `json:"inclusionPromise"`
+			CanonicalizedBody []byte `json:"canonicalizedBody"`
+		} `json:"tlogEntries"`
+	} `json:"verificationMaterial"`
+	MessageSignature *struct {
+		MessageDigest struct {
+			Algorithm string `json:"algorithm"`
+			Digest    []byte `json:"digest"`
+		} `json:"messageDigest"`
+		Signature []byte `json:"signature"`
+	} `json:"messageSignature"`
+	DSSEEnvelope *dsseEnvelope `json:"dsseEnvelope"`
+}
+
+// legacyCosignBundle is the bundle of `cosign sign-blob --bundle`, e.g., foo.tar.gz.bundle.
+type legacyCosignBundle struct {
+	Base64Signature string `json:"base64Signature"`
+	// base64 of the PEM certificate
+	Cert        string `json:"cert"`
+	RekorBundle *struct {
+		SignedEntryTimestamp []byte `json:"SignedEntryTimestamp"`
+		Payload              struct {
+			Body           string `json:"body"`
+			IntegratedTime int64  `json:"integratedTime"`
+			LogIndex       int64  `json:"logIndex"`
+			LogID          string `json:"logID"`
+		} `json:"Payload"`
+	} `json:"rekorBundle"`
+}
+
+// parseCosignBundle parses the Sigstore bundle, or the legacy cosign bundle.
+func parseCosignBundle(data []byte) (*cosignSignature, error) {
+	var probe struct {
+		MediaType       string `json:"mediaType"`
+		Base64Signature string `json:"base64Signature"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+
+	switch {
+	case strings.HasPrefix(probe.MediaType, "application/vnd.dev.sigstore.bundle"):
+		return parseSigstoreBundle(data)
+	case probe.Base64Signature != "":
+		return parseLegacyCosignBundle(data)
+	default:
+		return nil, errors.New("unsupported bundle")
+	}
+}
+
+func parseSigstoreBundle(data []byte) (*cosignSignature, error) {
+	var b sigstoreBundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	// DSSE envelopes are attestations rather than signatures of the asset, see slsaVerifier
+	if b.MessageSignature == nil {
+		return nil, errors.New("unsupported bundle: no message signature")
+	}
+
+	s := &cosignSignature{sig: b.MessageSignature.Signature}
+	if d := b.MessageSignature.MessageDigest; len(d.Digest) > 0 {
+		if d.Algorithm != "SHA2_256" {
+			return nil, fmt.Errorf("unsupported bundle digest: %s", d.Algorithm)
+		}
+		s.digest = d.Digest
+	}
+	var err error
+	if s.cert, s.chain, s.tlog, err = b.material(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// material returns the signing certificate and its chain, and the tlog entry with the inclusion promise if any.
+func (b *sigstoreBundle) material() (cert *x509.Certificate, chain []*x509.Certificate, tlog *tlogEntry, err error) {
+	var raws [][]byte
+	if c := b.VerificationMaterial.Certificate; c != nil {
+		raws = append(raws, c.RawBytes)
+	} else if c := b.VerificationMaterial.X509CertificateChain; c != nil {
+		for _, cert := range c.Certificates {
+			raws = append(raws, cert.RawBytes)
+		}
+	}
+	for _, raw := range raws {
+		c, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid bundle certificate: %w", err)
+		}
+		if cert == nil {
+			cert = c
+		} else {
+			chain = append(chain, c)
+		}
+	}
+
+	for _, e := range b.VerificationMaterial.TlogEntries {
+		if e.InclusionPromise == nil {
+			continue
+		}
+		tlog = &tlogEntry{
+			body:           e.CanonicalizedBody,
+			integratedTime: e.IntegratedTime,
+			logIndex:       e.LogIndex,
+			logID:          e.LogID.KeyID,
+			set:            e.InclusionPromise.SignedEntryTimestamp,
+		}
+		break
+	}
+	return cert, chain, tlog, nil
+}
+
+func parseLegacyCosignBundle(data []byte) (*cosignSignature, error) {
+	var b legacyCosignBundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(b.Base64Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle signature: %w", err)
+	}
+	s := &cosignSignature{sig: sig}
+
+	if b.Cert != "" {
+		certs, err := parseCertificates([]byte(b.Cert))
+		if err != nil {
+			return nil, fmt.Errorf("invalid bundle certificate: %w", err)
+		}
+		s.cert, s.chain = certs[0], certs[1:]
+	}
+
+	if r := b.RekorBundle; r != nil {
+		body, err := base64.StdEncoding.DecodeString(r.Payload.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bundle tlog entry: %w", err)
+		}
+		logID, err := hex.DecodeString(r.Payload.LogID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bundle tlog entry: %w", err)
+		}
+		s.tlog = &tlogEntry{
+			body:           body,
+			integratedTime: r.Payload.IntegratedTime,
+			logIndex:       r.Payload.LogIndex,
+			logID:          logID,
+			set:            r.SignedEntryTimestamp,
+		}
+	}
+	return s, nil
+}
+
+// trustedRoot is the Sigstore trusted root, i.e., trusted_root.json of the public good instance or a private deployment.
+type trustedRoot struct {
+	Tlogs []struct {
+		PublicKey struct {
+			RawBytes []byte   `json:"rawBytes"`
+			ValidFor validity `json:"validFor"`
+		} `json:"publicKey"`
+		LogID struct {
+			KeyID []byte `json:"keyId"`
+		} `json:"logId"`
+	} `json:"tlogs"`
+	CertificateAuthorities []struct {
+		CertChain struct {
+			Certificates []struct {
+				RawBytes []byte `json:"rawBytes"`
+			} `json:"certificates"`
+		} `json:"certChain"`
+		ValidFor validity `json:"validFor"`
+	} `json:"certificateAuthorities"`
+}
+
+type validity struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end"`
+}
+
+func (v validity) contains(t time.Time) bool {
+	return !t.Before(v.Start) && (v.End == nil || !t.After(*v.End))
+}
+
+func loadTrustedRoot(path string) (*trustedRoot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r trustedRoot
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("invalid trusted root %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// verifyTlogEntry verifies the signed entry timestamp by the key of the log.
+func (r *trustedRoot) verifyTlogEntry(e *tlogEntry) error {
+	for _, tlog := range r.Tlogs {
+		if !bytes.Equal(tlog.LogID.KeyID, e.logID) {
+			continue
+		}
+		if !tlog.PublicKey.ValidFor.contains(time.Unix(e.integratedTime, 0)) {
+			return fmt.Errorf("%w: tlog key of %x expired", ErrInvalidSignature, e.logID)
+		}
+		key, err := x509.ParsePKIXPublicKey(tlog.PublicKey.RawBytes)
+		if err != nil {
+			return fmt.Errorf("invalid trusted root tlog key: %w", err)
+		}
+
+		// canonical JSON of the entry, the keys are sorted by json.Marshal
+		payload, err := json.Marshal(map[string]any{
+			"body":           base64.StdEncoding.EncodeToString(e.body),
+			"integratedTime": e.integratedTime,
+			"logIndex":       e.logIndex,
+			"logID":          hex.EncodeToString(e.logID),
+		})
+		if err != nil {
+			return err
+		}
+		if err := verifyBlob(key, bytes.NewReader(payload), e.set); err != nil {
+			return fmt.Errorf("tlog entry: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: unknown tlog %x", ErrInvalidSignature, e.logID)
+}
+
+// verifyCertificate verifies the certificate is issued by a certificate authority of the trusted root at the time.
+func (r *trustedRoot) verifyCertificate(cert *x509.Certificate, chain []*x509.Certificate, at time.Time) error {
+	err := errors.New("no certificate authority")
+	for _, ca := range r.CertificateAuthorities {
+		if !ca.ValidFor.contains(at) || len(ca.CertChain.Certificates) == 0 {
+			continue
+		}
+
+		roots, intermediates := x509.NewCertPool(), x509.NewCertPool()
+		for _, c := range chain {
+			intermediates.AddCert(c)
+		}
+		// the chain is ordered from the intermediates to the root
+		for i, raw := range ca.CertChain.Certificates {
+			c, parseErr := x509.ParseCertificate(raw.RawBytes)
+			if parseErr != nil {
+				return fmt.Errorf("invalid trusted root certificate: %w", parseErr)
+			}
+			if i == len(ca.CertChain.Certificates)-1 {
+				roots.AddCert(c)
+			} else {
+				intermediates.AddCert(c)
+			}
+		}
+
+		if _, err = cert.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   at,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		}); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: untrusted certificate: %v", ErrInvalidSignature, err)
+}
+
+// verifyBlob verifies the signature of the content by the public key, ECDSA and RSA keys sign the digest of the content.
+func verifyBlob(key crypto.PublicKey, r io.Reader, sig []byte) error {
+	var ok bool
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		ok = ed25519.Verify(k, data, sig)
+	case *ecdsa.PublicKey:
+		h := crypto.SHA256
+		switch k.Curve {
+		case elliptic.P384():
+			h = crypto.SHA384
+		case elliptic.P521():
+			h = crypto.SHA512
+		}
+		digest, err := readerDigest(r, h)
+		if err != nil {
+			return err
+		}
+		ok = ecdsa.VerifyASN1(k, digest, sig)
+	case *rsa.PublicKey:
+		digest, err := readerDigest(r, crypto.SHA256)
+		if err != nil {
+			return err
+		}
+		ok = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, sig) == nil
+	default:
+		return fmt.Errorf("unsupported public key: %T", key)
+	}
+
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func readerDigest(r io.Reader, h crypto.Hash) ([]byte, error) {
+	hash := h.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+func fileDigest(path string, h crypto.Hash) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readerDigest(f, h)
+}
+
+// loadPublicKey loads the PEM public key, e.g., cosign.pub.
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || !strings.HasSuffix(block.Type, "PUBLIC KEY") {
+		return nil, fmt.Errorf("invalid public key %s: no PEM public key", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// parseCertificates parses the PEM certificates, which may be base64 encoded as cosign outputs, or a DER certificate.
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	data = bytes.TrimSpace(data)
+	if !bytes.HasPrefix(data, []byte("-----BEGIN")) {
+		if decoded, err := base64.StdEncoding.DecodeString(string(data)); err == nil {
+			data = bytes.TrimSpace(decoded)
+		}
+	}
+	if !bytes.HasPrefix(data, []byte("-----BEGIN")) {
+		cert, err := x509.ParseCertificate(data)
+		if err != nil {
+			return nil, err
+		}
+		return []*x509.Certificate{cert}, nil
+	}
+
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate")
+	}
+	return certs, nil
+}
+
+// decodeBase64OrRaw decodes the base64 signature as cosign outputs, or returns the raw signature.
+func decodeBase64OrRaw(data []byte) []byte {
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data))); err == nil {
+		return decoded
+	}
+	return data
+}
@@ -0,0 +1,489 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// sigstoreFixture is a private Sigstore instance, i.e., a Fulcio root, a Rekor key, and the trusted root of them.
+type sigstoreFixture struct {
+	caKey    *ecdsa.PrivateKey
+	ca       *x509.Certificate
+	rekorKey *ecdsa.PrivateKey
+	logID    []byte
+	rootPath string
+}
+
+func newSigstoreFixture(t *testing.T) *sigstoreFixture {
+	t.Helper()
+	f := &sigstoreFixture{caKey: newECDSAKey(t), rekorKey: newECDSAKey(t)}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fulcio"},
+		NotBefore:             time.Now().AddDate(-1, 0, 0),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &f.caKey.PublicKey, f.caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.ca, err = x509.ParseCertificate(der); err != nil {
+		t.Fatal(err)
+	}
+
+	rekorPub, err := x509.MarshalPKIXPublicKey(&f.rekorKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logID := sha256.Sum256(rekorPub)
+	f.logID = logID[:]
+
+	root := map[string]any{
+		"tlogs": []any{map[string]any{
+			"publicKey": map[string]any{"rawBytes": rekorPub, "validFor": map[string]any{"start": time.Now().AddDate(-1, 0, 0)}},
+			"logId":     map[string]any{"keyId": f.logID},
+		}},
+		"certificateAuthorities": []any{map[string]any{
+			"certChain": map[string]any{"certificates": []any{map[string]any{"rawBytes": der}}},
+			"validFor":  map[string]any{"start": time.Now().AddDate(-1, 0, 0)},
+		}},
+	}
+	f.rootPath = filepath.Join(t.TempDir(), "trusted_root.json")
+	writeJSON(t, f.rootPath, root)
+	return f
+}
+
+// sign signs the data by a short-lived certificate of the identity and the issuer, which is logged a day ago,
+// and returns the Sigstore bundle.
+func (f *sigstoreFixture) sign(t *testing.T, data []byte, identity, issuer string) map[string]any {
+	t.Helper()
+	signedAt := time.Now().AddDate(0, 0, -1)
+	key, cert := f.certificate(t, identity, issuer, signedAt)
+
+	digest := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"apiVersion": "0.0.1",
+		"kind":       "hashedrekord",
+		"spec": map[string]any{
+			"data":      map[string]any{"hash": map[string]any{"algorithm": "sha256", "value": hex.EncodeToString(digest[:])}},
+			"signature": map[string]any{"content": sig},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]any{
+		"mediaType": "application/vnd.dev.sigstore.bundle.v0.3+json",
+		"verificationMaterial": map[string]any{
+			"certificate": map[string]any{"rawBytes": cert},
+			"tlogEntries": []any{f.logEntry(t, "hashedrekord", body, signedAt)},
+		},
+		"messageSignature": map[string]any{
+			"messageDigest": map[string]any{"algorithm": "SHA2_256", "digest": digest[:]},
+			"signature":     sig,
+		},
+	}
+}
+
+// certificate issues a short-lived certificate of the identity, the issuer and the extensions, which is valid at signedAt.
+func (f *sigstoreFixture) certificate(t *testing.T, identity, issuer string, signedAt time.Time, exts ...pkix.Extension) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	key := newECDSAKey(t)
+	u, err := url.Parse(identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuerExt, err := asn1.Marshal(issuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		NotBefore:       signedAt.Add(-time.Minute),
+		NotAfter:        signedAt.Add(10 * time.Minute),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		URIs:            []*url.URL{u},
+		ExtraExtensions: append([]pkix.Extension{{Id: oidIssuerV2, Value: issuerExt}}, exts...),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, tmpl, f.ca, &key.PublicKey, f.caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, cert
+}
+
+// logEntry logs the body at signedAt, and returns the tlog entry of the bundle with the inclusion promise.
+func (f *sigstoreFixture) logEntry(t *testing.T, kind string, body []byte, signedAt time.Time) map[string]any {
+	t.Helper()
+	e := &tlogEntry{body: body, integratedTime: signedAt.Unix(), logIndex: 42, logID: f.logID}
+	payload, err := json.Marshal(map[string]any{
+		"body":           base64.StdEncoding.EncodeToString(body),
+		"integratedTime": e.integratedTime,
+		"logIndex":       e.logIndex,
+		"logID":          hex.EncodeToString(e.logID),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payloadDigest := sha256.Sum256(payload)
+	if e.set, err = ecdsa.SignASN1(rand.Reader, f.rekorKey, payloadDigest[:]); err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]any{
+		"logIndex":          "42",
+		"logId":             map[string]any{"keyId": f.logID},
+		"kindVersion":       map[string]any{"kind": kind, "version": "0.0.1"},
+		"integratedTime":    strconv.FormatInt(e.integratedTime, 10),
+		"inclusionPromise":  map[string]any{"signedEntryTimestamp": e.set},
+		"canonicalizedBody": body,
+	}
+}
+
+func newECDSAKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func writePublicKey(t *testing.T, path string, key crypto.PublicKey) {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCosignKeyVerification(t *testing.T) {
+	dir := t.TempDir()
+	artifact := filepath.Join(dir, "tool_linux_amd64.tar.gz")
+	if err := os.WriteFile(artifact, []byte("artifact"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tampered := filepath.Join(dir, "tampered.tar.gz")
+	if err := os.WriteFile(tampered, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ecKey := newECDSAKey(t)
+	digest := sha256.Sum256([]byte("artifact"))
+	ecSig, err := ecdsa.SignASN1(rand.Reader, ecKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edSig := ed25519.Sign(edKey, []byte("artifact"))
+
+	tests := []struct {
+		name    string
+		key     crypto.PublicKey
+		sig     []byte
+		path    string
+		wantErr error
+	}{
+		{"ecdsa", &ecKey.PublicKey, ecSig, artifact, nil},
+		{"ed25519", edPub, edSig, artifact, nil},
+		{"tampered", &ecKey.PublicKey, ecSig, tampered, ErrInvalidSignature},
+		{"another key", &newECDSAKey(t).PublicKey, ecSig, artifact, ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyPath := filepath.Join(t.TempDir(), "cosign.pub")
+			writePublicKey(t, keyPath, tt.key)
+			v, err := installOptions{packageOptions: packageOptions{CosignKey: keyPath}}.cosignVerifier(&Config{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// cosign outputs the base64 signature
+			s := &cosignSignature{sig: decodeBase64OrRaw([]byte(base64.StdEncoding.EncodeToString(tt.sig) + "\n"))}
+			if err := v.verifySignature(s, tt.path); !errors.Is(err, tt.wantErr) {
+				t.Errorf("verifySignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCosignKeylessVerification(t *testing.T) {
+	f := newSigstoreFixture(t)
+	const (
+		identity = "https://github.com/owner/repo/.github/workflows/release.yml@refs/tags/v1.0.0"
+		issuer   = "https://token.actions.githubusercontent.com"
+	)
+
+	artifact := filepath.Join(t.TempDir(), "tool_linux_amd64.tar.gz")
+	if err := os.WriteFile(artifact, []byte("artifact"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		identity string
+		issuer   string
+		modify   func(b map[string]any)
+		wantErr  error
+	}{
+		{name: "ok", identity: "^https://github.com/owner/repo/", issuer: issuer},
+		{name: "any issuer", identity: "^https://github.com/owner/repo/"},
+		{name: "another identity", identity: "^https://github.com/other/repo/", issuer: issuer, wantErr: ErrInvalidSignature},
+		{name: "another issuer", identity: "^https://github.com/owner/repo/", issuer: "https://gitlab.com", wantErr: ErrInvalidSignature},
+		{
+			name:     "forged integrated time",
+			identity: "^https://github.com/owner/repo/",
+			modify: func(b map[string]any) {
+				e := b["verificationMaterial"].(map[string]any)["tlogEntries"].([]any)[0].(map[string]any)
+				e["integratedTime"] = strconv.FormatInt(time.Now().Unix(), 10)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:     "no tlog entry",
+			identity: "^https://github.com/owner/repo/",
+			modify: func(b map[string]any) {
+				delete(b["verificationMaterial"].(map[string]any), "tlogEntries")
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:     "digest mismatch",
+			identity: "^https://github.com/owner/repo/",
+			modify: func(b map[string]any) {
+				digest := sha256.Sum256([]byte("another"))
+				b["messageSignature"].(map[string]any)["messageDigest"].(map[string]any)["digest"] = digest[:]
+			},
+			wantErr: ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := f.sign(t, []byte("artifact"), identity, issuer)
+			if tt.modify != nil {
+				tt.modify(b)
+			}
+			data, err := json.Marshal(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := parseCosignBundle(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v, err := installOptions{packageOptions: packageOptions{TrustedRoot: f.rootPath, CertIdentity: tt.identity, CertIssuer: tt.issuer}}.cosignVerifier(&Config{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := v.verifySignature(s, artifact); !errors.Is(err, tt.wantErr) {
+				t.Errorf("verifySignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseLegacyCosignBundle(t *testing.T) {
+	f := newSigstoreFixture(t)
+	b := f.sign(t, []byte("artifact"), "https://github.com/owner/repo/.github/workflows/release.yml@refs/tags/v1.0.0", "https://token.actions.githubusercontent.com")
+	s, err := parseCosignBundle(mustMarshal(t, b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacy := map[string]any{
+		"base64Signature": base64.StdEncoding.EncodeToString(s.sig),
+		"cert":            base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.cert.Raw})),
+		"rekorBundle": map[string]any{
+			"SignedEntryTimestamp": s.tlog.set,
+			"Payload": map[string]any{
+				"body":           base64.StdEncoding.EncodeToString(s.tlog.body),
+				"integratedTime": s.tlog.integratedTime,
+				"logIndex":       s.tlog.logIndex,
+				"logID":          hex.EncodeToString(s.tlog.logID),
+			},
+		},
+	}
+	got, err := parseCosignBundle(mustMarshal(t, legacy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	artifact := filepath.Join(t.TempDir(), "tool_linux_amd64.tar.gz")
+	if err := os.WriteFile(artifact, []byte("artifact"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	v := &cosignVerifier{root: mustLoadTrustedRoot(t, f.rootPath), identity: regexp.MustCompile("^https://github.com/owner/repo/")}
+	if err := v.verifySignature(got, artifact); err != nil {
+		t.Errorf("verifySignature() error = %v", err)
+	}
+
+	if _, err := parseCosignBundle([]byte(`{"mediaType": "application/vnd.dev.sigstore.bundle.v0.3+json", "dsseEnvelope": {}}`)); err == nil {
+		t.Error("parseCosignBundle() of DSSE envelope, want error")
+	}
+}
+
+func TestVerifyAsset(t *testing.T) {
+	key := newECDSAKey(t)
+	keyPath := filepath.Join(t.TempDir(), "cosign.pub")
+	writePublicKey(t, keyPath, &key.PublicKey)
+	v, err := installOptions{packageOptions: packageOptions{CosignKey: keyPath}}.cosignVerifier(&Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(content string) string {
+		digest := sha256.Sum256([]byte(content))
+		sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.StdEncoding.EncodeToString(sig)
+	}
+	var gpgSig bytes.Buffer
+	if err := openpgp.DetachSign(&gpgSig, newPGPEntity(t, "alice"), strings.NewReader("artifact"), testPGPConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	const asset, checksums = "tool_linux_amd64.tar.gz", "checksums.txt"
+	tests := []struct {
+		name string
+		// signature assets and their contents
+		signatures map[string]string
+		// verified asset
+		want    string
+		wantErr error
+	}{
+		{"asset", map[string]string{asset + ".sig": sign("artifact")}, asset, nil},
+		{"checksum file", map[string]string{checksums + ".sig": sign("checksums")}, checksums, nil},
+		{"invalid", map[string]string{asset + ".sig": sign("tampered"), checksums + ".sig": sign("checksums")}, "", ErrInvalidSignature},
+		// the .sig of gpg is not of cosign
+		{"gpg signature", map[string]string{asset + ".sig": gpgSig.String(), checksums + ".sig": sign("checksums")}, checksums, nil},
+		{"none", nil, "", ErrNoSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newAssetServer(t)
+			targets := []Asset{s.write(asset, "artifact"), s.write(checksums, "checksums")}
+			assets := slices.Clone(targets)
+			for name, content := range tt.signatures {
+				assets = append(assets, s.write(name, content))
+			}
+
+			verifications, err := s.verify(v, assets, targets...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("verifyAsset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (len(verifications) != 1 || verifications[0].Asset != tt.want) {
+				t.Errorf("verifyAsset() = %+v, want the verification of %s", verifications, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifiers(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "cosign.pub")
+	writePublicKey(t, keyPath, &newECDSAKey(t).PublicKey)
+
+	tests := []struct {
+		name    string
+		o       installOptions
+		want    int
+		wantErr bool
+	}{
+		{"none", installOptions{}, 0, false},
+		{"cosign key", installOptions{packageOptions: packageOptions{Verify: "cosign", CosignKey: keyPath}}, 1, false},
+		{"twice", installOptions{packageOptions: packageOptions{Verify: "cosign, cosign", CosignKey: keyPath}}, 1, false},
+		{"empty", installOptions{packageOptions: packageOptions{Verify: "cosign,,", CosignKey: keyPath}}, 1, false},
+		{"unknown", installOptions{packageOptions: packageOptions{Verify: "notary", CosignKey: keyPath}}, 0, true},
+		{"no key", installOptions{packageOptions: packageOptions{Verify: "cosign"}}, 0, true},
+		{"keyless without identity", installOptions{packageOptions: packageOptions{Verify: "cosign", TrustedRoot: newSigstoreFixture(t).rootPath}}, 0, true},
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.o.verifiers()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifiers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("verifiers() = %d verifiers, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func mustLoadTrustedRoot(t *testing.T, path string) *trustedRoot {
+	t.Helper()
+	r, err := loadTrustedRoot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"text/tabwriter"
+)
+
+// AssetScore is the scoring components of an asset, boolean components are scored 1 or 0.
+type AssetScore struct {
+	Name                   string `json:"name"`
+	ContainsOS             bool   `json:"contains_os"`
+	ContainsArch           bool   `json:"contains_arch"`
+	MatchesOS              bool   `json:"matches_os"`
+	MatchesArch            bool   `json:"matches_arch"`
+	SupportedArchiveFormat bool   `json:"supported_archive_format"`
+	Libc                   int    `json:"libc"`
+	MatchesARMVariant      bool   `json:"matches_arm_variant"`
+	MatchesPackageFormat   bool   `json:"matches_package_format"`
+	PreferredStatic        bool   `json:"preferred_static"`
+	// sum of the weights of the matched scoring rules
+	Rules        int    `json:"rules"`
+	MatchedRules string `json:"matched_rules,omitempty"`
+	Weight       int    `json:"weight"`
+	// why the asset is not a candidate, e.g., ignored file
+	Skipped  string `json:"skipped,omitempty"`
+	Selected bool   `json:"selected"`
+}
+
+// Explanation is how the asset of the release is selected.
+type Explanation struct {
+	Repo       string `json:"repo"`
+	Tag        string `json:"tag"`
+	Prerelease bool   `json:"prerelease"`
+	Platform   string `json:"platform"`
+	Pattern    string `json:"pattern,omitempty"`
+	// asset name rendered from the asset template
+	AssetName string       `json:"asset_name,omitempty"`
+	Assets    []AssetScore `json:"assets"`
+	Selected  string       `json:"selected,omitempty"`
+	// why the selected asset wins, or why none is selected
+	Reason string `json:"reason"`
+}
+
+func explainRelease(repo string, release Release, p Platform) Explanation {
+	e := Explanation{
+		Repo:       repo,
+		Tag:        release.TagName,
+		Prerelease: release.IsPrerelease(),
+		Platform:   p.String(),
+		Assets:     []AssetScore{},
+	}
+	if release.AssetPattern != nil {
+		e.Pattern = release.AssetPattern.String()
+	}
+	e.AssetName = release.AssetName
+
+	selected, reason, err := selectAsset(release, p)
+	if err != nil {
+		e.Reason = err.Error()
+	} else {
+		e.Selected = selected.Name
+		e.Reason = reason
+	}
+
+	for _, a := range release.Assets {
+		asset := newAsset(a.Name, a.URL, p)
+		asset.applyRules(release.ScoringRules)
+		score := AssetScore{
+			Name:                   asset.Name,
+			ContainsOS:             asset.containsOS,
+			ContainsArch:           asset.containsArch,
+			MatchesOS:              asset.matchesOS,
+			MatchesArch:            asset.matchesArch,
+			SupportedArchiveFormat: asset.supportedArchiveFormat,
+			Libc:                   asset.libc,
+			MatchesARMVariant:      asset.matchesARMVariant,
+			MatchesPackageFormat:   asset.matchesPackageFormat,
+			PreferredStatic:        asset.preferredStatic,
+			Rules:                  asset.ruleWeight,
+			MatchedRules:           asset.matchedRules,
+			Weight:                 asset.Weight(),
+			Selected:               err == nil && asset.Name == selected.Name,
+		}
+		switch {
+		case isIgnoredFile(asset.Name):
+			score.Skipped = "ignored file"
+		case release.AssetName != "" && asset.Name != release.AssetName:
+			score.Skipped = "not named by template"
+		case release.AssetPattern != nil && !release.AssetPattern.MatchString(asset.Name):
+			score.Skipped = "not matched by pattern"
+		case asset.incompatible:
+			score.Skipped = "newer ARM variant"
+		}
+		e.Assets = append(e.Assets, score)
+	}
+
+	return e
+}
+
+// explain resolves the release as install does, and explains the asset selection without installing.
+func explain(o installOptions) (*Explanation, error) {
+	p, err := o.platform()
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := o.scoringRules()
+	if err != nil {
+		return nil, err
+	}
+
+	var patternRe *regexp.Regexp
+	if o.Pattern != "" {
+		if patternRe, err = regexp.Compile(o.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	assetTmpl, aliases, err := o.assetTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := o.resolveProvider(); err != nil {
+		return nil, err
+	}
+	g, err := o.repoProvider()
+	if err != nil {
+		return nil, err
+	}
+	release, err := resolveRelease(g, o)
+	if err != nil {
+		return nil, err
+	}
+	release.AssetPattern = patternRe
+	if assetTmpl != nil {
+		if release.AssetName, err = renderAssetTemplate(assetTmpl, filepath.Base(o.Repo), release, p, aliases); err != nil {
+			return nil, err
+		}
+	}
+	release.ScoringRules = rules
+
+	e := explainRelease(o.Repo, release, p)
+	return &e, nil
+}
+
+func runExplain(args []string) error {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	var opts installOptions
+	releaseFlags(fs, &opts)
+	asJSON := fs.Bool("json", false, "print in JSON")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return errors.New("missing repo")
+	}
+	opts.Repo = fs.Arg(0)
+
+	e, err := explain(opts)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	} else {
+		tag := e.Tag
+		if e.Prerelease {
+			tag += " (pre-release)"
+		}
+		fmt.Printf("Release %s of %s for %s\n\n", tag, e.Repo, e.Platform)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ASSET\tcontainsOS\tcontainsArch\tmatchesOS\tmatchesArch\tsupportedArchiveFormat\tlibc\tmatchesARMVariant\tmatchesPackageFormat\tpreferredStatic\trules\tWEIGHT\tNOTE")
+		for _, a := range e.Assets {
+			note := a.Skipped
+			if a.Selected {
+				note = "selected"
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%+d\t%d\t%s\n", a.Name,
+				boolToInt(a.ContainsOS), boolToInt(a.ContainsArch), boolToInt(a.MatchesOS), boolToInt(a.MatchesArch),
+				boolToInt(a.SupportedArchiveFormat), a.Libc, boolToInt(a.MatchesARMVariant), boolToInt(a.MatchesPackageFormat),
+				boolToInt(a.PreferredStatic), a.Rules, a.Weight, note)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		if e.Selected != "" {
+			fmt.Printf("\nSelected %s: %s\n", e.Selected, e.Reason)
+		} else {
+			fmt.Printf("\nNo asset selected: %s\n", e.Reason)
+		}
+	}
+
+	if e.Selected == "" {
+		return exitError(1)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestExplainRelease(t *testing.T) {
+	release := Release{
+		TagName: "v1.8.2",
+		Assets: []Asset{
+			{Name: "node_exporter-1.8.2.linux-amd64.tar.gz"},
+			{Name: "node_exporter-1.8.2.linux-arm64.tar.gz"},
+			{Name: "node_exporter-1.8.2.darwin-amd64.tar.gz"},
+			{Name: "sha256sums.txt"},
+		},
+	}
+	p := Platform{"linux", "amd64", libcGNU}
+
+	tests := []struct {
+		name     string
+		pattern  string
+		selected string
+		reason   string
+		skipped  map[string]string
+	}{
+		{
+			name:     "max weight",
+			selected: "node_exporter-1.8.2.linux-amd64.tar.gz",
+			reason:   "the highest weight 6, the next is node_exporter-1.8.2.darwin-amd64.tar.gz of 5",
+			skipped:  map[string]string{"sha256sums.txt": "ignored file"},
+		},
+		{
+			name:     "pattern",
+			pattern:  "arm64",
+			selected: "node_exporter-1.8.2.linux-arm64.tar.gz",
+			reason:   "the only asset matched by pattern arm64",
+			skipped: map[string]string{
+				"node_exporter-1.8.2.linux-amd64.tar.gz":  "not matched by pattern",
+				"node_exporter-1.8.2.darwin-amd64.tar.gz": "not matched by pattern",
+				"sha256sums.txt": "ignored file",
+			},
+		},
+		{
+			name:    "no match",
+			pattern: "windows",
+			reason:  "No matched asset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := release
+			if tt.pattern != "" {
+				r.AssetPattern = regexp.MustCompile(tt.pattern)
+			}
+
+			e := explainRelease("prometheus/node_exporter", r, p)
+			if e.Selected != tt.selected || !strings.HasPrefix(e.Reason, tt.reason) {
+				t.Errorf("explainRelease() = %s: %s, want %s: %s", e.Selected, e.Reason, tt.selected, tt.reason)
+			}
+			if len(e.Assets) != len(release.Assets) {
+				t.Fatalf("explainRelease() has %d assets, want %d", len(e.Assets), len(release.Assets))
+			}
+			for _, a := range e.Assets {
+				if tt.skipped != nil && a.Skipped != tt.skipped[a.Name] {
+					t.Errorf("%s skipped = %q, want %q", a.Name, a.Skipped, tt.skipped[a.Name])
+				}
+				if a.Selected != (a.Name == tt.selected) {
+					t.Errorf("%s selected = %v", a.Name, a.Selected)
+				}
+			}
+		})
+	}
+}
+
+func TestExplainPrerelease(t *testing.T) {
+	p := Platform{"linux", "amd64", libcGNU}
+	tests := []struct {
+		release  Release
+		expected bool
+	}{
+		{Release{TagName: "v1.8.2"}, false},
+		{Release{TagName: "v1.8.2", Prerelease: true}, true},
+		{Release{TagName: "v1.9.0-rc.1"}, true},
+	}
+
+	for _, test := range tests {
+		if e := explainRelease("prometheus/node_exporter", test.release, p); e.Prerelease != test.expected {
+			t.Errorf("explainRelease(%+v).Prerelease = %v; expected %v", test.release, e.Prerelease, test.expected)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type GiteaAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+type GiteaRelease struct {
+	Name       string       `json:"name"`
+	TagName    string       `json:"tag_name"`
+	Draft      bool         `json:"draft"`
+	Prerelease bool         `json:"prerelease"`
+	Assets     []GiteaAsset `json:"assets"`
+}
+
+// Gitea also serves Forgejo and Codeberg, which share the same API.
+type Gitea struct {
+	apiURL      string
+	repo        string
+	authHeaders map[string]string
+}
+
+func NewGitea(giteaURL, token, repo string) *Gitea {
+	authHeaders := make(map[string]string)
+	if token != "" {
+		authHeaders["Authorization"] = "token " + token
+	}
+
+	return &Gitea{
+		apiURL:      giteaURL + "/api/v1",
+		repo:        repo,
+		authHeaders: authHeaders,
+	}
+}
+
+func (g *Gitea) GetLatestRelease() (Release, error) {
+	// https://gitea.com/api/swagger#/repository/repoGetLatestRelease
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", g.apiURL, g.repo)
+	return g.getRelease(url)
+}
+
+func (g *Gitea) GetTaggedRelease(tag string) (Release, error) {
+	// https://gitea.com/api/swagger#/repository/repoGetReleaseByTag
+	url := fmt.Sprintf("%s/repos/%s/releases/tags/%s", g.apiURL, g.repo, urlEncode(tag))
+	return g.getRelease(url)
+}
+
+func (g *Gitea) ListReleases() ([]Release, error) {
+	// https://gitea.com/api/swagger#/repository/repoListReleases
+	const perPage = 50
+	pageURL := func(page int) string {
+		return fmt.Sprintf("%s/repos/%s/releases?limit=%d&page=%d", g.apiURL, g.repo, perPage, page)
+	}
+	return listPagedReleases(pageURL, perPage, g.authHeaders, g.convertRelease)
+}
+
+func (g *Gitea) getRelease(url string) (Release, error) {
+	var gr GiteaRelease
+	if err := GetRelease(url, g.authHeaders, &gr); err != nil {
+		return Release{}, err
+	}
+
+	return g.convertRelease(gr), nil
+}
+
+func (g *Gitea) convertRelease(gr GiteaRelease) Release {
+	r := Release{
+		Name:        gr.Name,
+		TagName:     gr.TagName,
+		Prerelease:  gr.Prerelease,
+		Draft:       gr.Draft,
+		AuthHeaders: g.authHeaders,
+	}
+	for _, ga := range gr.Assets {
+		r.Assets = append(r.Assets, *NewAsset(ga.Name, ga.BrowserDownloadURL))
+	}
+	return r
+}
+
+// isGiteaURL reports whether the url looks like a Gitea-compatible forge.
+func isGiteaURL(u string) bool {
+	u = strings.ToLower(u)
+	for _, s := range []string{"gitea", "forgejo", "codeberg"} {
+		if strings.Contains(u, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsGiteaURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"https://gitea.com", true},
+		{"https://codeberg.org", true},
+		{"https://Forgejo.example.com", true},
+		{"https://gitlab.com", false},
+		{"https://git.example.com", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if result := isGiteaURL(test.url); result != test.expected {
+			t.Errorf("isGiteaURL(%s) = %v; expected %v", test.url, result, test.expected)
+		}
+	}
+}
+
+func TestGiteaLatestRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/owner/repo/releases/latest" || r.Header.Get("Authorization") != "token secret" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"tag_name": "v1.0.0", "assets": [{"name": "tool_linux_amd64.tar.gz", "browser_download_url": "https://codeberg.org/owner/repo/releases/download/v1.0.0/tool_linux_amd64.tar.gz"}]}`)
+	}))
+	defer server.Close()
+
+	release, err := NewGitea(server.URL, "secret", "owner/repo").GetLatestRelease()
+	if err != nil {
+		t.Fatalf("GetLatestRelease() error = %v", err)
+	}
+	if release.TagName != "v1.0.0" || len(release.Assets) != 1 || release.Assets[0].Name != "tool_linux_amd64.tar.gz" {
+		t.Errorf("GetLatestRelease() = %+v", release)
+	}
+	if release.AuthHeaders["Authorization"] != "token secret" {
+		t.Errorf("GetLatestRelease() auth headers = %v, want the token", release.AuthHeaders)
+	}
+}
@@ -48,6 +48,15 @@ func (g *GitHub) GetTaggedRelease(tag string) (Release, error) {
 	return g.getRelease(url)
 }
 
+func (g *GitHub) ListReleases() ([]Release, error) {
+	// https://docs.github.com/en/rest/releases/releases?apiVersion=2022-11-28#list-releases
+	const perPage = 100
+	pageURL := func(page int) string {
+		return fmt.Sprintf("https://api.github.com/repos/%s/releases?per_page=%d&page=%d", g.repo, perPage, page)
+	}
+	return listPagedReleases(pageURL, perPage, g.authHeaders, g.convertRelease)
+}
+
 func (g *GitHub) getRelease(url string) (Release, error) {
 	var gr GitHubRelease
 	if err := GetRelease(url, g.authHeaders, &gr); err != nil {
@@ -66,6 +75,8 @@ func (g *GitHub) convertRelease(gr GitHubRelease) Release {
 	r := Release{
 		Name:        gr.Name,
 		TagName:     gr.TagName,
+		Prerelease:  gr.Prerelease,
+		Draft:       gr.Draft,
 		AuthHeaders: headers,
 	}
 	for _, ga := range gr.Assets {
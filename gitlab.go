@@ -63,6 +63,15 @@ func (g *GitLab) GetTaggedRelease(tag string) (Release, error) {
 	return g.getRelease(url)
 }
 
+func (g *GitLab) ListReleases() ([]Release, error) {
+	// https://docs.gitlab.com/ee/api/releases/#list-releases
+	const perPage = 100
+	pageURL := func(page int) string {
+		return fmt.Sprintf("%s/projects/%s/releases?per_page=%d&page=%d", g.apiURL, g.projectID, perPage, page)
+	}
+	return listPagedReleases(pageURL, perPage, g.authHeaders, g.convertRelease)
+}
+
 func (g *GitLab) getRelease(url string) (Release, error) {
 	// https://docs.gitlab.com/ee/api/releases/#get-the-latest-release
 	var gr GitLabRelease
@@ -77,6 +86,7 @@ func (g *GitLab) convertRelease(gr GitLabRelease) Release {
 	r := Release{
 		Name:        gr.Name,
 		TagName:     gr.TagName,
+		Draft:       gr.UpcomingRelease,
 		AuthHeaders: g.authHeaders,
 	}
 	for _, link := range gr.Assets.Links {
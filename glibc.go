@@ -0,0 +1,138 @@
+package main
+
+import (
+	"debug/elf"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// glibc older than the version prefers static assets, e.g., CentOS 7 has 2.17, and Debian 11 has 2.31
+const staticGlibcThreshold = "2.31"
+
+var ErrGlibcVersion = errors.New("glibc version not satisfied")
+
+var (
+	lddVersionRe    = regexp.MustCompile(`(?i)(glibc|gnu libc).*?(\d+\.\d+)\s*$`)
+	glibcSymbolRe   = regexp.MustCompile(`GLIBC_(\d+\.\d+(?:\.\d+)?)`)
+	libcSearchPaths = []string{
+		"/lib64/libc.so.6",
+		"/lib/libc.so.6",
+		"/usr/lib64/libc.so.6",
+		"/lib/*/libc.so.6",
+		"/usr/lib/*/libc.so.6",
+	}
+)
+
+// hostGlibcVersion returns the glibc version of the host, e.g., 2.36, or empty if not glibc.
+var hostGlibcVersion = sync.OnceValue(detectGlibcVersion)
+
+func detectGlibcVersion() string {
+	if out, err := exec.Command("ldd", "--version").Output(); err == nil {
+		if v := parseLddVersion(string(out)); v != "" {
+			return v
+		}
+	}
+
+	// the highest GLIBC_x.y version defined in libc.so.6
+	for _, pattern := range libcSearchPaths {
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			if v := maxGlibcVersion(glibcSymbolRe.FindAllSubmatch(data, -1)); v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// parseLddVersion parses the first line of ldd --version, e.g., ldd (Debian GLIBC 2.36-9+deb12u4) 2.36,
+// musl prints its version to stderr, which is not glibc.
+func parseLddVersion(out string) string {
+	line, _, _ := strings.Cut(out, "\n")
+	if m := lddVersionRe.FindStringSubmatch(line); m != nil {
+		return m[2]
+	}
+	return ""
+}
+
+func maxGlibcVersion(matches [][][]byte) string {
+	var max string
+	for _, m := range matches {
+		if v := string(m[1]); max == "" || compareVersions(v, max) > 0 {
+			max = v
+		}
+	}
+	return max
+}
+
+// isOldGlibc reports whether the glibc version is older than the static threshold.
+func isOldGlibc(v string) bool {
+	return v != "" && compareVersions(v, staticGlibcThreshold) < 0
+}
+
+// glibcRequirement returns the highest GLIBC_x.y symbol version required by the binary, or empty.
+func glibcRequirement(f *elf.File) (string, error) {
+	symbols, err := f.ImportedSymbols()
+	if err != nil {
+		// no dynamic symbols, e.g., static binary
+		if errors.Is(err, elf.ErrNoSymbols) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	var matches [][][]byte
+	for _, sym := range symbols {
+		if m := glibcSymbolRe.FindSubmatch([]byte(sym.Version)); m != nil {
+			matches = append(matches, m)
+		}
+	}
+	return maxGlibcVersion(matches), nil
+}
+
+// checkGlibcVersion checks the glibc symbol versions required by the binary against the host glibc.
+func checkGlibcVersion(f *elf.File, name string) error {
+	host := hostGlibcVersion()
+	if host == "" {
+		return nil
+	}
+
+	required, err := glibcRequirement(f)
+	if err != nil {
+		return fmt.Errorf("invalid ELF binary %s: %w", name, err)
+	}
+	if required != "" && compareVersions(required, host) > 0 {
+		return fmt.Errorf("%w: %s requires GLIBC_%s, but the host has %s", ErrGlibcVersion, name, required, host)
+	}
+	return nil
+}
+
+// findFallbackAsset returns the musl or static asset for the target, which does not depend on the host glibc.
+func findFallbackAsset(release Release, p Platform) (Asset, bool) {
+	var assets Assets
+	for _, a := range release.Assets {
+		if isIgnoredFile(a.Name) || !(containsMusl(a.Name) || containsStatic(a.Name)) {
+			continue
+		}
+		// rescore as musl, which prefers musl to others
+		p.Libc = libcMusl
+		asset := newAsset(a.Name, a.URL, p)
+		asset.applyRules(release.ScoringRules)
+		if asset.matchesOS && asset.matchesArch {
+			assets = append(assets, *asset)
+		}
+	}
+
+	asset, err := assets.FindMaxWeightAsset()
+	return asset, err == nil
+}
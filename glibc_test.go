@@ -0,0 +1,133 @@
+package main
+
+import (
+	"debug/elf"
+	"errors"
+	"testing"
+)
+
+func TestParseLddVersion(t *testing.T) {
+	tests := []struct {
+		out      string
+		expected string
+	}{
+		{"ldd (Debian GLIBC 2.36-9+deb12u4) 2.36\nCopyright (C) 2022 Free Software Foundation, Inc.\n", "2.36"},
+		{"ldd (GNU libc) 2.17\nCopyright (C) 2012 Free Software Foundation, Inc.\n", "2.17"},
+		{"ldd (Ubuntu GLIBC 2.35-0ubuntu3.8) 2.35\n", "2.35"},
+		{"musl libc (x86_64)\nVersion 1.2.4\n", ""},
+	}
+
+	for _, test := range tests {
+		if result := parseLddVersion(test.out); result != test.expected {
+			t.Errorf("parseLddVersion(%q) = %s; expected %s", test.out, result, test.expected)
+		}
+	}
+}
+
+func TestCheckGlibcVersion(t *testing.T) {
+	f, err := elf.Open("/bin/sh")
+	if err != nil {
+		t.Skip("no ELF /bin/sh")
+	}
+	defer f.Close()
+	required, err := glibcRequirement(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if required == "" {
+		t.Skip("/bin/sh does not require glibc")
+	}
+
+	defer func(v func() string) { hostGlibcVersion = v }(hostGlibcVersion)
+	tests := []struct {
+		host    string
+		wantErr error
+	}{
+		{"", nil},
+		{required, nil},
+		{"2.0", ErrGlibcVersion},
+	}
+
+	for _, tt := range tests {
+		hostGlibcVersion = func() string { return tt.host }
+		if err := checkGlibcVersion(f, "sh"); !errors.Is(err, tt.wantErr) {
+			t.Errorf("checkGlibcVersion() with glibc %q error = %v, wantErr %v", tt.host, err, tt.wantErr)
+		}
+	}
+}
+
+func TestStaticAsset(t *testing.T) {
+	// the host glibc only applies to the host
+	if !(Platform{"linux", "amd64", libcGNU}).isHost() {
+		t.Skip("not a linux/amd64 host")
+	}
+	defer func(v func() string) { hostGlibcVersion = v }(hostGlibcVersion)
+
+	names := []string{
+		"example-1.0.0-linux-amd64.tar.gz",
+		"example-1.0.0-linux-amd64-static.tar.gz",
+		"example-1.0.0-linux-arm64-static.tar.gz",
+	}
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"2.17", "example-1.0.0-linux-amd64-static.tar.gz"},
+		{"2.36", "example-1.0.0-linux-amd64.tar.gz"},
+		// unknown, e.g., the version is not detected
+		{"", "example-1.0.0-linux-amd64.tar.gz"},
+	}
+
+	for _, tt := range tests {
+		hostGlibcVersion = func() string { return tt.host }
+		var assets Assets
+		for _, name := range names {
+			assets = append(assets, *newAsset(name, "", Platform{"linux", "amd64", libcGNU}))
+		}
+		if got, err := assets.FindMaxWeightAsset(); got.Name != tt.want {
+			t.Errorf("FindMaxWeightAsset() with glibc %s = %s, %v, want %s", tt.host, got.Name, err, tt.want)
+		}
+	}
+}
+
+func TestFindFallbackAsset(t *testing.T) {
+	tests := []struct {
+		name   string
+		assets []string
+		want   string
+	}{
+		{
+			name: "musl",
+			assets: []string{
+				"ripgrep-14.1.0-x86_64-unknown-linux-gnu.tar.gz",
+				"ripgrep-14.1.0-x86_64-unknown-linux-musl.tar.gz",
+				"ripgrep-14.1.0-aarch64-unknown-linux-musl.tar.gz",
+				"ripgrep-14.1.0-x86_64-unknown-linux-musl.tar.gz.sha256",
+			},
+			want: "ripgrep-14.1.0-x86_64-unknown-linux-musl.tar.gz",
+		},
+		{
+			name:   "static",
+			assets: []string{"example-linux-amd64", "example-linux-amd64-static"},
+			want:   "example-linux-amd64-static",
+		},
+		{
+			name:   "no fallback",
+			assets: []string{"example-linux-amd64", "example-linux-arm64-static"},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var release Release
+			for _, name := range tt.assets {
+				release.Assets = append(release.Assets, Asset{Name: name})
+			}
+			got, ok := findFallbackAsset(release, Platform{"linux", "amd64", libcGNU})
+			if ok != (tt.want != "") || got.Name != tt.want {
+				t.Errorf("findFallbackAsset() = %s, %v, want %s", got.Name, ok, tt.want)
+			}
+		})
+	}
+}
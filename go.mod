@@ -2,4 +2,16 @@ module github.com/zachcheung/release-installer
 
 go 1.22.5
 
-require golang.org/x/net v0.27.0
+require (
+	github.com/ProtonMail/go-crypto v1.3.0
+	github.com/klauspost/compress v1.18.0
+	github.com/ulikunitz/xz v0.5.12
+	golang.org/x/crypto v0.33.0
+	golang.org/x/net v0.27.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/cloudflare/circl v1.6.1 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+)
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+)
+
+const (
+	armoredPublicKeyHeader = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+	armoredSignatureHeader = "-----BEGIN PGP SIGNATURE-----"
+)
+
+var (
+	// detached signatures of the asset, e.g., foo.tar.gz.asc, SHA256SUMS.gpg, .sig is also of cosign
+	gpgSignatureSuffixes = []string{".asc", ".sig", ".gpg"}
+	// v4 or v6 fingerprint
+	fingerprintRe = regexp.MustCompile(`^([0-9A-F]{40}|[0-9A-F]{64})$`)
+)
+
+// gpgVerifier verifies the detached OpenPGP signatures by the keyring,
+// the signing key must be one of the allowed fingerprints if any.
+type gpgVerifier struct {
+	keyring openpgp.EntityList
+	// fingerprints of the primary keys
+	fingerprints []string
+}
+
+func (o installOptions) gpgVerifier(config *Config) (*gpgVerifier, error) {
+	path := cmp.Or(o.GPGKeyring, config.GPG.Keyring)
+	if path == "" {
+		return nil, errors.New("gpg verification requires -gpg-keyring")
+	}
+
+	keyring, err := loadKeyring(path)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &gpgVerifier{keyring: keyring}
+	for _, fpr := range slices.Concat(o.GPGFingerprints, config.GPG.Fingerprints[o.Repo]) {
+		fpr = normalizeFingerprint(fpr)
+		if !fingerprintRe.MatchString(fpr) {
+			return nil, fmt.Errorf("invalid gpg fingerprint: %s", fpr)
+		}
+		v.fingerprints = append(v.fingerprints, fpr)
+	}
+	return v, nil
+}
+
+func (v *gpgVerifier) name() string {
+	return verifyGPG
+}
+
+func (v *gpgVerifier) verify(release Release, target Asset, path, destDir string) (*Verification, error) {
+	a, ok := findSignatureAsset(release.Assets, target.Name, gpgSignatureSuffixes...)
+	if !ok {
+		return nil, nil
+	}
+	sig, err := downloadSignature(release, a, destDir)
+	if err != nil {
+		return nil, err
+	}
+	if !isOpenPGPSignature(sig) {
+		log.Printf("%s is not an OpenPGP signature", a.Name)
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var signer *openpgp.Entity
+	if bytes.HasPrefix(bytes.TrimSpace(sig), []byte(armoredSignatureHeader)) {
+		signer, err = openpgp.CheckArmoredDetachedSignature(v.keyring, f, bytes.NewReader(sig), nil)
+	} else {
+		signer, err = openpgp.CheckDetachedSignature(v.keyring, f, bytes.NewReader(sig), nil)
+	}
+	if err != nil {
+		if errors.Is(err, pgperrors.ErrUnknownIssuer) {
+			return nil, fmt.Errorf("%w: %s is signed by a key not in the keyring", ErrInvalidSignature, a.Name)
+		}
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidSignature, a.Name, err)
+	}
+
+	fpr := strings.ToUpper(hex.EncodeToString(signer.PrimaryKey.Fingerprint))
+	if len(v.fingerprints) > 0 && !slices.Contains(v.fingerprints, fpr) {
+		return nil, fmt.Errorf("%w: signed by %s, which is not an allowed fingerprint", ErrInvalidSignature, fpr)
+	}
+	log.Printf("%s is signed by %s %s", target.Name, fpr, primaryIdentity(signer))
+	return &Verification{Signature: a.Name, Signer: fpr}, nil
+}
+
+// isOpenPGPSignature reports whether the data is an armored or binary OpenPGP signature,
+// instead of a cosign signature of the same .sig suffix, which is base64 or DER.
+func isOpenPGPSignature(data []byte) bool {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armoredSignatureHeader)) {
+		return true
+	}
+	if len(data) == 0 || data[0]&0x80 == 0 {
+		return false
+	}
+	// the packet tag of the old or new format, 2 is the signature packet
+	tag := data[0] & 0x3f
+	if data[0]&0x40 == 0 {
+		tag = (data[0] >> 2) & 0x0f
+	}
+	return tag == 2
+}
+
+// loadKeyring loads the binary or armored keyring, which may have many armored keys, e.g., KEYS of Apache projects.
+func loadKeyring(path string) (openpgp.EntityList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keyring openpgp.EntityList
+	if !bytes.Contains(data, []byte(armoredPublicKeyHeader)) {
+		if keyring, err = openpgp.ReadKeyRing(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("invalid gpg keyring %s: %w", path, err)
+		}
+	} else {
+		blocks := strings.Split(string(data), armoredPublicKeyHeader)
+		for _, block := range blocks[1:] {
+			entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredPublicKeyHeader + block))
+			if err != nil {
+				return nil, fmt.Errorf("invalid gpg keyring %s: %w", path, err)
+			}
+			keyring = append(keyring, entities...)
+		}
+	}
+
+	if len(keyring) == 0 {
+		return nil, fmt.Errorf("invalid gpg keyring %s: no key", path)
+	}
+	return keyring, nil
+}
+
+// normalizeFingerprint returns the fingerprint in upper case without spaces, e.g., of `gpg --fingerprint`.
+func normalizeFingerprint(fpr string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimPrefix(fpr, "0x"), " ", ""))
+}
+
+func primaryIdentity(e *openpgp.Entity) string {
+	if id := e.PrimaryIdentity(); id != nil {
+		return id.Name
+	}
+	return ""
+}
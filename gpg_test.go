@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+var testPGPConfig = &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA}
+
+func newPGPEntity(t *testing.T, name string) *openpgp.Entity {
+	t.Helper()
+	e, err := openpgp.NewEntity(name, "", name+"@example.com", testPGPConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func armoredPublicKey(t *testing.T, e *openpgp.Entity) string {
+	t.Helper()
+	var b bytes.Buffer
+	w, err := armor.Encode(&b, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestLoadKeyring(t *testing.T) {
+	alice, bob := newPGPEntity(t, "alice"), newPGPEntity(t, "bob")
+	dir := t.TempDir()
+
+	// KEYS of Apache projects, the keys are armored with the listing of gpg
+	keys := filepath.Join(dir, "KEYS")
+	data := "pub   ed25519 2024-01-01\nuid   alice\n\n" + armoredPublicKey(t, alice) + "\npub   ed25519 2024-01-01\nuid   bob\n\n" + armoredPublicKey(t, bob)
+	if err := os.WriteFile(keys, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := loadKeyring(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keyring) != 2 {
+		t.Errorf("loadKeyring(KEYS) = %d keys, want 2", len(keyring))
+	}
+
+	var b bytes.Buffer
+	if err := alice.Serialize(&b); err != nil {
+		t.Fatal(err)
+	}
+	pubring := filepath.Join(dir, "pubring.gpg")
+	if err := os.WriteFile(pubring, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if keyring, err = loadKeyring(pubring); err != nil || len(keyring) != 1 {
+		t.Errorf("loadKeyring(pubring.gpg) = %d keys, %v, want 1", len(keyring), err)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadKeyring(empty); err == nil {
+		t.Error("loadKeyring(empty), want error")
+	}
+}
+
+func TestNormalizeFingerprint(t *testing.T) {
+	tests := map[string]string{
+		"0123 4567 89AB CDEF 0123  4567 89AB CDEF 0123 4567": "0123456789ABCDEF0123456789ABCDEF01234567",
+		"0x0123456789abcdef0123456789abcdef01234567":         "0123456789ABCDEF0123456789ABCDEF01234567",
+	}
+	for fpr, want := range tests {
+		if got := normalizeFingerprint(fpr); got != want {
+			t.Errorf("normalizeFingerprint(%s) = %s, want %s", fpr, got, want)
+		}
+	}
+}
+
+func TestGPGVerification(t *testing.T) {
+	alice, mallory := newPGPEntity(t, "alice"), newPGPEntity(t, "mallory")
+	aliceFpr := strings.ToUpper(hex.EncodeToString(alice.PrimaryKey.Fingerprint))
+	malloryFpr := strings.ToUpper(hex.EncodeToString(mallory.PrimaryKey.Fingerprint))
+
+	keyring := filepath.Join(t.TempDir(), "KEYS")
+	if err := os.WriteFile(keyring, []byte(armoredPublicKey(t, alice)+armoredPublicKey(t, mallory)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sign := func(signer *openpgp.Entity, content string, armored bool) string {
+		var b bytes.Buffer
+		var err error
+		if armored {
+			err = openpgp.ArmoredDetachSign(&b, signer, strings.NewReader(content), testPGPConfig)
+		} else {
+			err = openpgp.DetachSign(&b, signer, strings.NewReader(content), testPGPConfig)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b.String()
+	}
+
+	const asset, checksums = "apache-tool-1.0.0-bin.tar.gz", "SHA256SUMS"
+	tests := []struct {
+		name string
+		// signature assets and their contents
+		signatures   map[string]string
+		fingerprints []string
+		wantErr      error
+	}{
+		{"armored", map[string]string{asset + ".asc": sign(alice, "artifact", true)}, nil, nil},
+		{"binary", map[string]string{asset + ".sig": sign(alice, "artifact", false)}, nil, nil},
+		{"checksum file", map[string]string{checksums + ".asc": sign(alice, "checksums", true)}, nil, nil},
+		{"allowed", map[string]string{asset + ".asc": sign(alice, "artifact", true)}, []string{aliceFpr, malloryFpr}, nil},
+		{"not allowed", map[string]string{asset + ".asc": sign(mallory, "artifact", true)}, []string{aliceFpr}, ErrInvalidSignature},
+		{"unknown key", map[string]string{asset + ".asc": sign(newPGPEntity(t, "eve"), "artifact", true)}, nil, ErrInvalidSignature},
+		{"tampered", map[string]string{asset + ".asc": sign(alice, "tampered", true)}, nil, ErrInvalidSignature},
+		// the .sig of cosign is not of gpg
+		{"cosign signature", map[string]string{asset + ".sig": "MEUCIQDx"}, nil, ErrNoSignature},
+		{"none", nil, nil, ErrNoSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := installOptions{packageOptions: packageOptions{Repo: "apache/tool", GPGKeyring: keyring, GPGFingerprints: tt.fingerprints}}
+			v, err := o.gpgVerifier(&Config{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s := newAssetServer(t)
+			targets := []Asset{s.write(asset, "artifact"), s.write(checksums, "checksums")}
+			assets := slices.Clone(targets)
+			for name, content := range tt.signatures {
+				assets = append(assets, s.write(name, content))
+			}
+			if _, err := s.verify(v, assets, targets...); !errors.Is(err, tt.wantErr) {
+				t.Errorf("verifyAsset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsOpenPGPSignature(t *testing.T) {
+	var armored, binary bytes.Buffer
+	signer := newPGPEntity(t, "alice")
+	if err := openpgp.ArmoredDetachSign(&armored, signer, strings.NewReader("artifact"), testPGPConfig); err != nil {
+		t.Fatal(err)
+	}
+	if err := openpgp.DetachSign(&binary, signer, strings.NewReader("artifact"), testPGPConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		data []byte
+		want bool
+	}{
+		"armored":       {armored.Bytes(), true},
+		"binary":        {binary.Bytes(), true},
+		"cosign base64": {[]byte("MEUCIQDx"), false},
+		"cosign DER":    {[]byte{0x30, 0x45, 0x02, 0x21}, false},
+		"public key":    {[]byte{0x99, 0x01, 0x0d}, false},
+		"empty":         {nil, false},
+	}
+	for name, tt := range tests {
+		if got := isOpenPGPSignature(tt.data); got != tt.want {
+			t.Errorf("isOpenPGPSignature(%s) = %v, want %v", name, got, tt.want)
+		}
+	}
+}
+
+func TestGPGFingerprintsOfRepo(t *testing.T) {
+	keyring := filepath.Join(t.TempDir(), "KEYS")
+	if err := os.WriteFile(keyring, []byte(armoredPublicKey(t, newPGPEntity(t, "alice"))), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{GPG: GPGConfig{
+		Keyring:      keyring,
+		Fingerprints: map[string][]string{"apache/tool": {"0123 4567 89AB CDEF 0123  4567 89AB CDEF 0123 4567"}},
+	}}
+
+	v, err := installOptions{packageOptions: packageOptions{Repo: "apache/tool"}}.gpgVerifier(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"0123456789ABCDEF0123456789ABCDEF01234567"}; !slices.Equal(v.fingerprints, want) {
+		t.Errorf("gpgVerifier() fingerprints = %v, want %v", v.fingerprints, want)
+	}
+	if v, err = (installOptions{packageOptions: packageOptions{Repo: "other/tool"}}).gpgVerifier(config); err != nil || len(v.fingerprints) != 0 {
+		t.Errorf("gpgVerifier() of another repo = %v, %v, want no fingerprints", v, err)
+	}
+	if _, err := (installOptions{packageOptions: packageOptions{GPGKeyring: keyring, GPGFingerprints: []string{"ABCD"}}}).gpgVerifier(&Config{}); err == nil {
+		t.Error("gpgVerifier() of invalid fingerprint, want error")
+	}
+
+	// -gpg-keyring implies -verify gpg, which is verified once
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	verifiers, err := installOptions{packageOptions: packageOptions{Verify: "gpg", GPGKeyring: keyring}}.verifiers()
+	if err != nil || len(verifiers) != 1 || verifiers[0].name() != verifyGPG {
+		t.Errorf("verifiers() = %v, %v, want gpg", verifiers, err)
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const defaultInstallDir = "/usr/local/bin"
+
+var ErrEmptyAssets = errors.New("empty release assets")
+
+type installOptions struct {
+	packageOptions
+	Token string
+	Tag   string
+}
+
+// packageOptions are how a package is installed, which are listed in the manifest,
+// and recorded in the install to install it again.
+type packageOptions struct {
+	Provider string `json:"provider" yaml:"provider"`
+	URL      string `json:"url,omitempty" yaml:"url"`
+	// name of the environment variable holding the token
+	TokenEnv string `json:"token_env,omitempty" yaml:"token_env"`
+	Repo     string `json:"repo" yaml:"repo"`
+	// version constraint, e.g., ^1.2, resolved against all releases
+	Constraint string `json:"constraint,omitempty" yaml:"version"`
+	// allow pre-releases when resolving the latest release
+	AllowPrerelease bool `json:"allow_prerelease,omitempty" yaml:"prerelease"`
+	// allow draft and upcoming releases
+	AllowDraft bool `json:"allow_draft,omitempty" yaml:"draft"`
+
+	Dir     string `json:"dir" yaml:"dir"`
+	Pattern string `json:"pattern,omitempty" yaml:"pattern"`
+	Exclude string `json:"exclude,omitempty" yaml:"exclude"`
+	// target platform, defaults to the host
+	OS   string `json:"os,omitempty" yaml:"os"`
+	Arch string `json:"arch,omitempty" yaml:"arch"`
+	// libc of the target, gnu, musl or auto
+	Libc string `json:"libc,omitempty" yaml:"libc"`
+	// config file, defaults to $XDG_CONFIG_HOME/release-installer/config.yaml
+	Config string `json:"config,omitempty" yaml:"config"`
+	// scoring rules of REGEX=DELTA
+	Rules []string `json:"rules,omitempty" yaml:"rules"`
+	// asset name template, e.g., {{.Name}}_{{.Version}}_{{.OS}}_{{.Arch}}.tar.gz, and the aliases of VALUE=ALIAS
+	AssetTemplate string   `json:"asset_template,omitempty" yaml:"asset_template"`
+	Aliases       []string `json:"aliases,omitempty" yaml:"aliases"`
+	// signature verifications, e.g., cosign
+	Verify string `json:"verify,omitempty" yaml:"verify"`
+	// cosign public key or trusted root, and the identity and OIDC issuer of keyless signatures
+	CosignKey    string `json:"cosign_key,omitempty" yaml:"cosign_key"`
+	TrustedRoot  string `json:"trusted_root,omitempty" yaml:"trusted_root"`
+	CertIdentity string `json:"certificate_identity_regexp,omitempty" yaml:"certificate_identity_regexp"`
+	CertIssuer   string `json:"certificate_oidc_issuer,omitempty" yaml:"certificate_oidc_issuer"`
+	// gpg keyring, and the allowed fingerprints of the signing keys
+	GPGKeyring      string   `json:"gpg_keyring,omitempty" yaml:"gpg_keyring"`
+	GPGFingerprints []string `json:"gpg_fingerprints,omitempty" yaml:"gpg_fingerprints"`
+	// minisign public key, or the file of it
+	MinisignKey string `json:"minisign_key,omitempty" yaml:"minisign_key"`
+	// expected builder ID and source repo of the SLSA provenance
+	SLSABuilderID string `json:"slsa_builder_id,omitempty" yaml:"slsa_builder_id"`
+	SLSASourceURI string `json:"slsa_source_uri,omitempty" yaml:"slsa_source_uri"`
+}
+
+// resolveProvider fills in the default url of the provider, or infers the provider from the url.
+func (o *installOptions) resolveProvider() error {
+	if o.Provider == "gitlab" && o.URL == "" {
+		o.URL = "https://gitlab.com"
+	}
+	if o.Provider == "gitea" && o.URL == "" {
+		o.URL = "https://gitea.com"
+	}
+	if o.Provider == "" && strings.Contains(strings.ToLower(o.URL), "gitlab") {
+		o.Provider = "gitlab"
+	}
+	if o.Provider == "" && isGiteaURL(o.URL) {
+		o.Provider = "gitea"
+	}
+	if o.Provider == "apache" && o.URL == "" {
+		return errors.New("-url is required with apache provider")
+	}
+	if o.Provider == "" {
+		o.Provider = "github"
+	}
+	return nil
+}
+
+func (o installOptions) repoProvider() (RepoProvider, error) {
+	switch o.Provider {
+	case "github":
+		return NewGitHub(o.Token, o.Repo), nil
+	case "gitlab":
+		return NewGitLab(o.URL, o.Token, o.Repo), nil
+	case "gitea":
+		return NewGitea(o.URL, o.Token, o.Repo), nil
+	case "apache":
+		return NewApache(o.URL), nil
+	default:
+		return nil, fmt.Errorf("unsupported provider: %s", o.Provider)
+	}
+}
+
+// install installs the release asset of the repo, and records the install.
+func install(o installOptions) (*InstallRecord, error) {
+	var (
+		patternRe *regexp.Regexp
+		excludeRe *regexp.Regexp
+		err       error
+	)
+	if o.Pattern != "" {
+		if patternRe, err = regexp.Compile(o.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	if o.Exclude != "" {
+		if excludeRe, err = regexp.Compile(o.Exclude); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern: %w", err)
+		}
+	}
+	assetTmpl, aliases, err := o.assetTemplate()
+	if err != nil {
+		return nil, err
+	}
+	if err := o.resolveProvider(); err != nil {
+		return nil, err
+	}
+	// the source repo of the provenance defaults to the repo of the provider
+	verifiers, err := o.verifiers()
+	if err != nil {
+		return nil, err
+	}
+
+	platform, err := o.platform()
+	if err != nil {
+		return nil, err
+	}
+	if !platform.isHost() {
+		log.Printf("Installing for %s", platform)
+	}
+	rules, err := o.scoringRules()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(o.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating directory: %w", err)
+	}
+	installDir, err := filepath.Abs(o.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving directory: %w", err)
+	}
+
+	g, err := o.repoProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := resolveRelease(g, o)
+	if err != nil {
+		if errors.Is(err, ErrNoRelease) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error getting release: %w", err)
+	}
+	if release.IsPrerelease() {
+		log.Printf("Release %s is a pre-release", release.TagName)
+	}
+
+	if len(release.Assets) == 0 {
+		return nil, ErrEmptyAssets
+	}
+
+	if patternRe != nil {
+		release.AssetPattern = patternRe
+	}
+	if assetTmpl != nil {
+		if release.AssetName, err = renderAssetTemplate(assetTmpl, filepath.Base(o.Repo), release, platform, aliases); err != nil {
+			return nil, err
+		}
+	}
+	release.ScoringRules = rules
+	release.Verifiers = verifiers
+
+	tempDir, err := os.MkdirTemp("", "release-installer")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temp dir: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// the files of the previous install are owned, and removed if not installed again
+	previous, _ := previousInstall(InstallRecord{packageOptions: packageOptions{Provider: o.Provider, URL: o.URL, Repo: o.Repo, Dir: installDir}})
+
+	// use repo base as filename
+	asset, sum, files, verifications, err := installReleaseAsset(release, platform, tempDir, installDir, filepath.Base(o.Repo), excludeRe, previous.Files)
+	if errors.Is(err, ErrGlibcVersion) && patternRe == nil && assetTmpl == nil {
+		// the musl or static asset does not depend on the host glibc
+		fallback, ok := findFallbackAsset(release, platform)
+		if !ok {
+			return nil, err
+		}
+		log.Printf("%v, falling back to %s", err, fallback.Name)
+		release.AssetPattern = regexp.MustCompile("^" + regexp.QuoteMeta(fallback.Name) + "$")
+		asset, sum, files, verifications, err = installReleaseAsset(release, platform, tempDir, installDir, filepath.Base(o.Repo), excludeRe, previous.Files)
+	}
+	if err != nil {
+		return nil, err
+	}
+	removeOrphanFiles(previous.Files, files)
+
+	record := &InstallRecord{
+		packageOptions: o.packageOptions,
+		Tag:            release.TagName,
+		Prerelease:     release.IsPrerelease(),
+		Asset:          asset.Name,
+		DownloadURL:    asset.URL,
+		SHA256:         hex.EncodeToString(sum),
+		Files:          files,
+		InstalledAt:    time.Now(),
+		Verifications:  verifications,
+	}
+	record.Dir = installDir
+	if err := recordInstall(*record); err != nil {
+		log.Printf("Error saving install state: %v", err)
+	}
+
+	return record, nil
+}
+
+// installReleaseAsset downloads and installs the asset of the release, and returns the asset, its SHA256, the installed files and the verifications,
+// a single binary is installed as the name, i.e., repo base, unless derived from the compressed asset name.
+// An identical file already installed is returned only if it is owned, i.e., installed by the previous install.
+func installReleaseAsset(release Release, p Platform, tempDir, installDir, name string, excludeRe *regexp.Regexp, owned []string) (Asset, []byte, []string, []Verification, error) {
+	asset, fpath, verifications, err := downloadReleaseAsset(release, p, tempDir)
+	if err != nil {
+		return Asset{}, nil, nil, nil, fmt.Errorf("error downloading asset: %w", err)
+	}
+
+	sum, err := calculateSHA256(fpath)
+	if err != nil {
+		return Asset{}, nil, nil, nil, err
+	}
+
+	// the extraction is decided by the content, the name may be misleading, e.g., an HTML error page
+	content, compression, err := sniffContent(fpath)
+	if err != nil {
+		return Asset{}, nil, nil, nil, err
+	}
+
+	var files []string
+	if !isExecutableContent(content) {
+		if files, err = extractAndInstallExecutables(fpath, content, compression, installDir, excludeRe, p, owned); err != nil {
+			return Asset{}, nil, nil, nil, fmt.Errorf("error installing package: %w", err)
+		}
+	} else {
+		if compression != compressionNone {
+			name = binaryName(asset.Name, name)
+			decompressedPath := filepath.Join(tempDir, "decompressed", name)
+			if err := os.MkdirAll(filepath.Dir(decompressedPath), 0755); err != nil {
+				return Asset{}, nil, nil, nil, err
+			}
+			if err := decompressFile(fpath, decompressedPath, compression); err != nil {
+				return Asset{}, nil, nil, nil, fmt.Errorf("error decompressing asset: %w", err)
+			}
+			fpath = decompressedPath
+		}
+		if err := validateBinary(fpath, p); err != nil {
+			return Asset{}, nil, nil, nil, err
+		}
+		destPath := filepath.Join(installDir, name)
+		isSameFile, err := isIdenticalFile(fpath, destPath)
+		if err != nil {
+			return Asset{}, nil, nil, nil, err
+		}
+		if isSameFile {
+			log.Printf("%s is identical, no need to install", destPath)
+			if slices.Contains(owned, destPath) {
+				files = []string{destPath}
+			}
+		} else {
+			if err := addExecutePermission(fpath); err != nil {
+				return Asset{}, nil, nil, nil, fmt.Errorf("error adding execute permission: %w", err)
+			}
+			if err := os.Rename(fpath, destPath); err != nil {
+				return Asset{}, nil, nil, nil, fmt.Errorf("error installing package: %w", err)
+			}
+			log.Printf("Installed %s as %s", asset.Name, destPath)
+			files = []string{destPath}
+		}
+	}
+
+	return asset, sum, files, verifications, nil
+}
+
+// resolveRelease returns the tagged release, the latest release satisfying the constraint,
+// or the latest release. Drafts are refused, and pre-releases are skipped unless allowed.
+func resolveRelease(g RepoProvider, o installOptions) (Release, error) {
+	if o.Tag != "" && o.Constraint != "" {
+		return Release{}, errors.New("tag and version constraint are mutually exclusive")
+	}
+
+	tag := o.Tag
+	if o.Constraint != "" || (tag == "" && o.AllowPrerelease) {
+		// GetLatestRelease never returns pre-releases
+		var (
+			c   *Constraint
+			err error
+		)
+		if o.Constraint != "" {
+			if c, err = ParseConstraint(o.Constraint); err != nil {
+				return Release{}, err
+			}
+		}
+		releases, err := g.ListReleases()
+		if err != nil {
+			return Release{}, err
+		}
+
+		matched := findLatestRelease(releases, c, o.AllowPrerelease, o.AllowDraft)
+		if matched == nil {
+			if c != nil {
+				return Release{}, fmt.Errorf("%w satisfying %s", ErrNoRelease, c)
+			}
+			return Release{}, ErrNoRelease
+		}
+		if c != nil {
+			log.Printf("Resolved %s to %s", c, matched.TagName)
+		}
+		tag = matched.TagName
+	}
+
+	if tag == "" {
+		return g.GetLatestRelease()
+	}
+
+	release, err := g.GetTaggedRelease(tag)
+	if err != nil && errors.Is(err, ErrNoRelease) && !strings.HasPrefix(tag, "v") {
+		// try again with v prefix
+		vTag := "v" + tag
+		release, err = g.GetTaggedRelease(vTag)
+	}
+	if err == nil && release.Draft && !o.AllowDraft {
+		return Release{}, fmt.Errorf("release %s is a draft or upcoming release, use -draft to install it", release.TagName)
+	}
+	return release, err
+}
+
+// findLatestRelease returns the release of the highest version satisfying the constraint,
+// or the first one in the listing order, i.e., the newest, if no tag is a version.
+func findLatestRelease(releases []Release, c *Constraint, prerelease, draft bool) *Release {
+	var (
+		latest  *Release
+		first   *Release
+		checked bool
+	)
+	for i, r := range releases {
+		if (r.Draft && !draft) || (r.IsPrerelease() && !prerelease) {
+			continue
+		}
+		if first == nil {
+			first = &releases[i]
+		}
+		if !isVersion(r.TagName) {
+			continue
+		}
+		checked = true
+		if c != nil && !c.Check(r.TagName) {
+			continue
+		}
+		if latest == nil || compareVersions(r.TagName, latest.TagName) > 0 {
+			latest = &releases[i]
+		}
+	}
+
+	if !checked && c == nil {
+		return first
+	}
+	return latest
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFindLatestRelease(t *testing.T) {
+	releases := []Release{
+		{TagName: "v3.0.0", Draft: true},
+		{TagName: "v2.1.0-rc.1"},
+		{TagName: "v2.0.0"},
+		{TagName: "v1.10.0"},
+		{TagName: "v1.9.0", Prerelease: true},
+		{TagName: "nightly"},
+	}
+	caret1, _ := ParseConstraint("^1")
+
+	tests := []struct {
+		name       string
+		constraint *Constraint
+		prerelease bool
+		draft      bool
+		want       string
+	}{
+		{"latest", nil, false, false, "v2.0.0"},
+		{"prerelease", nil, true, false, "v2.1.0-rc.1"},
+		{"draft", nil, true, true, "v3.0.0"},
+		{"constraint", caret1, false, false, "v1.10.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findLatestRelease(releases, tt.constraint, tt.prerelease, tt.draft)
+			if got == nil || got.TagName != tt.want {
+				t.Errorf("findLatestRelease() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,32 +6,39 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
-	"regexp"
 	"strings"
 )
 
 var (
-	installDir   string
-	provider     string
-	baseURL      string
-	token        string
-	tag          string
-	repo         string
-	pattern      string
-	exclude      string
 	printVersion bool
 	version      string
 )
 
+// exitError exits with the code without printing anything else.
+type exitError int
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
 func main() {
-	flag.StringVar(&installDir, "dir", "/usr/local/bin", "installation directory")
-	flag.StringVar(&provider, "provider", "", "repo provider, default is github, options: github, gitlab, apache")
-	flag.StringVar(&baseURL, "url", "", "base url, e.g., https://gitlab.example.com")
-	flag.StringVar(&token, "token", "", "token for private repo")
-	flag.StringVar(&tag, "tag", "", "tag name, v can be omitted")
-	flag.StringVar(&pattern, "pattern", "", "match asset by regexp")
-	flag.StringVar(&exclude, "exclude", "", "exclude binaries of asset by regexp")
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				var code exitError
+				if errors.As(err, &code) {
+					os.Exit(int(code))
+				}
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
+	var opts installOptions
+	flag.StringVar(&opts.Dir, "dir", defaultInstallDir, "installation directory")
+	flag.StringVar(&opts.Exclude, "exclude", "", "exclude binaries of asset by regexp")
+	releaseFlags(flag.CommandLine, &opts)
 	flag.BoolVar(&printVersion, "version", false, "print version")
 	flag.Parse()
 
@@ -44,116 +51,57 @@ func main() {
 		fmt.Println("Missing repo")
 		os.Exit(1)
 	}
-	repo = flag.Arg(0)
-
-	var (
-		patternRe *regexp.Regexp
-		excludeRe *regexp.Regexp
-		err       error
-	)
-	if pattern != "" {
-		if patternRe, err = regexp.Compile(pattern); err != nil {
-			log.Fatalf("Invalid pattern: %v", err)
-		}
-	}
-	if exclude != "" {
-		if excludeRe, err = regexp.Compile(exclude); err != nil {
-			log.Fatalf("Invalid exclude pattern: %v", err)
-		}
-	}
-
-	if err := os.MkdirAll(installDir, 0755); err != nil {
-		log.Fatalf("Error creating directory: %v", err)
-	}
+	opts.Repo = flag.Arg(0)
 
-	if provider == "gitlab" && baseURL == "" {
-		baseURL = "https://gitlab.com"
-	}
-	if provider == "" && strings.Contains(strings.ToLower(baseURL), "gitlab") {
-		provider = "gitlab"
-	}
-	if provider == "apache" && baseURL == "" {
-		log.Fatalf("-url is required with apache provider")
-	}
-	if provider == "" {
-		provider = "github"
-	}
-
-	var g RepoProvider
-	switch provider {
-	case "github":
-		g = NewGitHub(token, repo)
-	case "gitlab":
-		g = NewGitLab(baseURL, token, repo)
-	case "apache":
-		g = NewApache(baseURL)
-	default:
-		log.Fatalf("unsupported provider: %s", provider)
-	}
-
-	var release Release
-	if tag == "" {
-		release, err = g.GetLatestRelease()
-	} else {
-		release, err = g.GetTaggedRelease(tag)
-		if err != nil && errors.Is(err, ErrNoRelease) && !strings.HasPrefix(tag, "v") {
-			// try again with v prefix
-			vTag := "v" + tag
-			release, err = g.GetTaggedRelease(vTag)
-		}
-	}
-	if err != nil {
-		if errors.Is(err, ErrNoRelease) {
+	if _, err := install(opts); err != nil {
+		switch {
+		case errors.Is(err, ErrNoRelease):
 			log.Print("No release found")
-			return
-		} else {
-			log.Fatalf("Error getting release: %v", err)
+		case errors.Is(err, ErrEmptyAssets):
+			log.Print("Empty release assets")
+		default:
+			log.Fatal(err)
 		}
 	}
+}
 
-	if len(release.Assets) == 0 {
-		log.Print("Empty release assets")
-		return
-	}
-
-	if patternRe != nil {
-		release.AssetPattern = patternRe
-	}
+// releaseFlags defines the flags selecting the release and its asset, which are shared by install and explain.
+func releaseFlags(fs *flag.FlagSet, opts *installOptions) {
+	fs.StringVar(&opts.Provider, "provider", "", "repo provider, default is github, options: github, gitlab, gitea, apache")
+	fs.StringVar(&opts.URL, "url", "", "base url, e.g., https://gitlab.example.com")
+	fs.StringVar(&opts.Token, "token", "", "token for private repo")
+	fs.StringVar(&opts.Tag, "tag", "", "tag name, v can be omitted")
+	fs.StringVar(&opts.Constraint, "constraint", "", "version constraint, e.g., ^1.2, ~1.2.3, 1.x, '>=2.3, <3'")
+	fs.BoolVar(&opts.AllowPrerelease, "prerelease", false, "allow pre-releases when resolving the latest release")
+	fs.BoolVar(&opts.AllowDraft, "draft", false, "allow draft and upcoming releases")
+	fs.StringVar(&opts.Pattern, "pattern", "", "match asset by regexp")
+	fs.StringVar(&opts.OS, "os", "", "OS of the target, default is the host OS, e.g., linux, darwin, windows")
+	fs.StringVar(&opts.Arch, "arch", "", "arch of the target, default is the host arch, e.g., amd64, arm64, armv7")
+	fs.StringVar(&opts.Libc, "libc", "", "libc of the target, default is auto, options: gnu, musl, auto")
+	fs.StringVar(&opts.Config, "config", "", "config file, default is $XDG_CONFIG_HOME/release-installer/config.yaml")
+	fs.Var((*stringsFlag)(&opts.Rules), "rule", "scoring rule of REGEX=DELTA, e.g., '\\.zip$=-1', can be repeated")
+	fs.StringVar(&opts.AssetTemplate, "asset-template", "", "asset name template, e.g., '{{.Name}}_{{.Version}}_{{.OS}}_{{.Arch}}.tar.gz'")
+	fs.StringVar(&opts.Verify, "verify", "", "verify the signature of the asset or its checksum file, comma separated, options: cosign, gpg, minisign, slsa")
+	fs.StringVar(&opts.CosignKey, "cosign-key", "", "cosign public key, e.g., cosign.pub")
+	fs.StringVar(&opts.TrustedRoot, "trusted-root", "", "Sigstore trusted root of keyless cosign signatures, e.g., trusted_root.json")
+	fs.StringVar(&opts.CertIdentity, "certificate-identity-regexp", "", "identity of the keyless signing certificate, e.g., '^https://github.com/owner/repo/'")
+	fs.StringVar(&opts.CertIssuer, "certificate-oidc-issuer", "", "OIDC issuer of the keyless signing certificate, e.g., https://token.actions.githubusercontent.com")
+	fs.StringVar(&opts.GPGKeyring, "gpg-keyring", "", "keyring verifying the gpg signatures, e.g., KEYS, implies -verify gpg")
+	fs.Var((*stringsFlag)(&opts.GPGFingerprints), "gpg-fingerprint", "allowed fingerprint of the gpg signing key, can be repeated")
+	fs.StringVar(&opts.MinisignKey, "minisign-key", "", "minisign public key, or the file of it, implies -verify minisign")
+	fs.StringVar(&opts.SLSABuilderID, "slsa-builder-id", "", "builder ID of the SLSA provenance without the version, implies -verify slsa")
+	fs.StringVar(&opts.SLSASourceURI, "slsa-source-uri", "", "source repo of the SLSA provenance, default is the repo, e.g., github.com/owner/repo, implies -verify slsa")
+	fs.Var((*stringsFlag)(&opts.Aliases), "alias", "alias of the OS, arch or libc in the asset template, e.g., amd64=x86_64, can be repeated")
+}
 
-	tempDir, err := os.MkdirTemp("", "release-installer")
-	if err != nil {
-		log.Fatalf("Error creating temp dir: %v", err)
-	}
-	defer os.RemoveAll(tempDir)
+// stringsFlag is a flag which can be repeated.
+type stringsFlag []string
 
-	fpath, err := downloadReleaseAsset(release, tempDir)
-	if err != nil {
-		log.Fatalf("Error downloading asset: %v", err)
-	}
-
-	if isSupportedArchiveFormat(fpath) {
-		if err := extractAndInstallExecutables(fpath, installDir, excludeRe); err != nil {
-			log.Fatalf("Error installing package: %v", err)
-		}
-	} else {
-		// use repo base as filename
-		name := filepath.Base(repo)
-		destPath := filepath.Join(installDir, name)
-		isSameFile, err := isIdenticalFile(fpath, destPath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		if isSameFile {
-			log.Printf("%s is identical, no need to install", destPath)
-			return
-		}
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ", ")
+}
 
-		if err := addExecutePermission(fpath); err != nil {
-			log.Fatalf("Error adding execute permission: %v", err)
-		}
-		if err := os.Rename(fpath, destPath); err != nil {
-			log.Fatalf("Error installing package: %v", err)
-		}
-		log.Printf("Installed %s as %s", filepath.Base(fpath), destPath)
-	}
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
 }
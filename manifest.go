@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest lists the packages to install in one run, e.g.,
+//
+//	dir: /usr/local/bin
+//	tools:
+//	  - repo: prometheus/node_exporter
+//	  - repo: goreleaser/example
+//	    provider: gitlab
+//	    token_env: GITLAB_TOKEN
+type Manifest struct {
+	// default installation directory of the entries
+	Dir   string          `yaml:"dir"`
+	Tools []ManifestEntry `yaml:"tools"`
+}
+
+type ManifestEntry struct {
+	packageOptions `yaml:",inline"`
+	Tag            string `yaml:"tag"`
+}
+
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+
+	for i, e := range m.Tools {
+		if e.Repo == "" {
+			return nil, fmt.Errorf("invalid manifest %s: missing repo of tools[%d]", path, i)
+		}
+	}
+
+	return &m, nil
+}
+
+func (m Manifest) options(e ManifestEntry) installOptions {
+	o := installOptions{packageOptions: e.packageOptions, Token: os.Getenv(e.TokenEnv), Tag: e.Tag}
+	if o.Dir == "" {
+		o.Dir = m.Dir
+	}
+	if o.Dir == "" {
+		o.Dir = defaultInstallDir
+	}
+	return o
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.yaml")
+	data := `dir: /opt/bin
+tools:
+  - repo: prometheus/node_exporter
+    version: ^1.8
+    prerelease: true
+  - repo: goreleaser/example
+    provider: gitlab
+    draft: true
+    dir: /usr/local/bin
+    token_env: TEST_GITLAB_TOKEN
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_GITLAB_TOKEN", "secret")
+
+	m, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	if len(m.Tools) != 2 {
+		t.Fatalf("LoadManifest() got %d tools, want 2", len(m.Tools))
+	}
+
+	if o := m.options(m.Tools[0]); o.Dir != "/opt/bin" || o.Constraint != "^1.8" || !o.AllowPrerelease {
+		t.Errorf("options() = %+v", o)
+	}
+	if o := m.options(m.Tools[1]); o.Dir != "/usr/local/bin" || o.Provider != "gitlab" || o.Token != "secret" || !o.AllowDraft {
+		t.Errorf("options() = %+v", o)
+	}
+}
+
+func TestLoadManifestMissingRepo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.yaml")
+	if err := os.WriteFile(path, []byte("tools:\n  - tag: v1.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadManifest(path); err == nil {
+		t.Error("LoadManifest() error = nil, want missing repo error")
+	}
+}
@@ -1,18 +1,33 @@
 package main
 
 import (
-	"path/filepath"
+	"fmt"
+	"os"
 	"regexp"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strconv"
 	"strings"
 )
 
 var (
 	hashFileRe    = regexp.MustCompile(`(checksums?|(md5|sha1|sha128|sha256|sha512)(sums?)?)\b`)
 	ignoredFileRe = regexp.MustCompile(`\.(txt|json)$`)
-	isMusl        = isMuslLibcPresent()
-	muslRe        = regexp.MustCompile(`[ -_\.]musl[ -_\.]?\b`)
+	// signatures and certificates of the assets, e.g., foo.tar.gz.sig, foo.tar.gz.pem and foo.tar.gz.bundle
+	signatureFileRe = regexp.MustCompile(`\.(sig|asc|gpg|minisig|pem|crt|cert|bundle|intoto\.jsonl|sigstore)$`)
+	hostARM         = detectARMVersion()
+	armVersionRe    = regexp.MustCompile(`(?i)(?:CPU architecture\s*:\s*|ARMv)(\d+)`)
+	muslRe          = regexp.MustCompile(`[ -_\.]musl[ -_\.]?\b`)
+	versionRe       = regexp.MustCompile(`\d+(\.\d+)+`)
+	// OS with the bitness, e.g., linux64 of jq
+	osBitnessRe = regexp.MustCompile(`^(linux|darwin|macos|osx|freebsd|netbsd|openbsd)(32|64)$`)
+	// x86-64 microarchitecture levels, e.g., amd64v3, the 64v3 of x86_64v3
+	microarchRe = regexp.MustCompile(`^(amd64|x64|64)v[1-4]$`)
 )
 
+const tokenSeparators = "-_. "
+
 // https://github.com/golang/go/blob/go1.22.5/src/go/build/syslist.go
 var knownOS = map[string]bool{
 	"aix":       true,
@@ -65,94 +80,232 @@ var knownArch = map[string]bool{
 var knownOSAliases = map[string][]string{
 	"darwin": []string{
 		"mac",
+		"macos",
+		"osx",
 	},
 	"linux": []string{
 		"lnx",
 	},
 	"windows": []string{
 		"win",
+		"win32",
+		"win64",
 	},
 }
 
 var knownArchAliases = map[string][]string{
+	"386": []string{
+		"i386",
+		"i686",
+		"x86",
+		"32bit",
+	},
 	"amd64": []string{
 		"x86_64",
 		"x64",
 		"64bit",
 	},
+	"arm": []string{
+		"armv5",
+		"armv5l",
+		"armel",
+		"arm5",
+		"armv6",
+		"armv6l",
+		"armv6hf",
+		"arm6",
+		"armv7",
+		"armv7l",
+		"armv7hf",
+		"armv7hl",
+		"armhf",
+		"arm7",
+	},
 	"arm64": []string{
 		"aarch64",
 	},
 }
 
+// 32-bit ARM variants (GOARM) of the arm aliases
+var knownARMVariants = map[string]int{
+	"armv5":   5,
+	"armv5l":  5,
+	"armel":   5,
+	"arm5":    5,
+	"armv6":   6,
+	"armv6l":  6,
+	"armv6hf": 6,
+	"arm6":    6,
+	// Raspbian armhf targets ARMv6, Debian armhf ARMv7, so the lower one
+	"armhf":   6,
+	"armv7":   7,
+	"armv7l":  7,
+	"armv7hf": 7,
+	"armv7hl": 7,
+	"arm7":    7,
+}
+
+// tokenize splits the lowercase name into tokens on separators, version numbers are dropped
+// so that e.g. 386 in 1.386.0 is not taken as an arch. The OS with the bitness is split into the OS and
+// the bitness, e.g., linux64 into linux and 64bit, and the microarchitecture level is dropped, e.g., amd64v3.
+func tokenize(name string) []string {
+	name = versionRe.ReplaceAllString(strings.ToLower(name), " ")
+	var tokens []string
+	for _, token := range strings.FieldsFunc(name, func(r rune) bool {
+		return strings.ContainsRune(tokenSeparators, r)
+	}) {
+		if m := osBitnessRe.FindStringSubmatch(token); m != nil {
+			tokens = append(tokens, m[1], m[2]+"bit")
+			continue
+		}
+		if m := microarchRe.FindStringSubmatch(token); m != nil {
+			token = m[1]
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
 func containsOS(name string) bool {
-	return mapContains(name, knownOS, knownOSAliases)
+	return len(findOS(name)) > 0
 }
 
 func containsArch(name string) bool {
-	return mapContains(name, knownArch, knownArchAliases)
+	return len(findArch(name)) > 0
 }
 
-func mapContains(name string, m map[string]bool, aliases map[string][]string) bool {
-	name = strings.ToLower(name)
-	for k, _ := range m {
-		if strings.Contains(name, k) {
-			return true
-		}
+func matchesOS(name, goos string) bool {
+	return slices.Contains(findOS(name), goos)
+}
+
+func matchesArch(name, goarch string) bool {
+	return slices.Contains(findArch(name), goarch)
+}
+
+// findOS returns the known OSes in the name.
+func findOS(name string) []string {
+	return findTerms(tokenize(name), knownOS, knownOSAliases)
+}
+
+// findArch returns the known archs in the name.
+func findArch(name string) []string {
+	return findTerms(tokenize(name), knownArch, knownArchAliases)
+}
+
+// findTerms scans the tokens for the known terms and their aliases, an alias may span multiple
+// tokens, e.g., x86_64, the longest one wins at each position so that x86 is not found in x86_64.
+func findTerms(tokens []string, m map[string]bool, aliases map[string][]string) []string {
+	type candidate struct {
+		tokens []string
+		term   string
 	}
 
-	for _, v := range aliases {
+	var candidates []candidate
+	for k := range m {
+		candidates = append(candidates, candidate{tokenize(k), k})
+	}
+	for k, v := range aliases {
 		for _, alias := range v {
-			if strings.Contains(name, alias) {
-				return true
+			candidates = append(candidates, candidate{tokenize(alias), k})
+		}
+	}
+
+	var terms []string
+	for i := 0; i < len(tokens); {
+		var matched *candidate
+		for j, c := range candidates {
+			if len(c.tokens) > len(tokens)-i || (matched != nil && len(c.tokens) <= len(matched.tokens)) {
+				continue
 			}
+			if slices.Equal(tokens[i:i+len(c.tokens)], c.tokens) {
+				matched = &candidates[j]
+			}
+		}
+
+		if matched == nil {
+			i++
+			continue
 		}
+		if !slices.Contains(terms, matched.term) {
+			terms = append(terms, matched.term)
+		}
+		i += len(matched.tokens)
 	}
 
-	return false
+	return terms
 }
 
-func matchesOS(name, goos string) bool {
-	return mapMatches(name, goos, knownOSAliases)
+// findARMVariant returns the highest ARM variant in the name, 0 if there is no variant, e.g., arm.
+func findARMVariant(name string) int {
+	var variant int
+	for _, token := range tokenize(name) {
+		variant = max(variant, knownARMVariants[token])
+	}
+	return variant
 }
 
-func matchesArch(name, goarch string) bool {
-	return mapMatches(name, goarch, knownArchAliases)
+// parseArch splits the ARM variant from the arch, e.g., armv7 is arm with variant 7.
+func parseArch(goarch string) (string, int) {
+	if variant, ok := knownARMVariants[goarch]; ok {
+		return "arm", variant
+	}
+	return goarch, 0
 }
 
-func mapMatches(name, target string, targetAliases map[string][]string) bool {
-	name = strings.ToLower(name)
-	if strings.Contains(name, target) {
-		return true
-	} else if aliases, ok := targetAliases[target]; ok {
-		if containsAlias(name, aliases) {
-			return true
-		}
+// hostArch returns GOARCH with the ARM variant of the host, e.g., armv7.
+func hostArch() string {
+	if runtime.GOARCH == "arm" && hostARM > 0 {
+		return fmt.Sprintf("armv%d", hostARM)
 	}
-	return false
+	return runtime.GOARCH
 }
 
-func containsAlias(name string, aliases []string) bool {
-	name = strings.ToLower(name)
-	for _, v := range aliases {
-		if strings.Contains(name, v) {
-			return true
+// detectARMVersion returns the ARM version of the host, falling back to GOARM of the build.
+func detectARMVersion() int {
+	if runtime.GOARCH != "arm" {
+		return 0
+	}
+
+	if data, err := os.ReadFile("/proc/cpuinfo"); err == nil {
+		if v := parseARMVersion(string(data)); v > 0 {
+			return v
 		}
 	}
 
-	return false
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			// e.g., 7 or 6,softfloat
+			if setting.Key == "GOARM" {
+				v, _ := strconv.Atoi(strings.Split(setting.Value, ",")[0])
+				return v
+			}
+		}
+	}
+
+	return 0
+}
+
+// parseARMVersion parses the ARM version from the content of /proc/cpuinfo.
+func parseARMVersion(cpuinfo string) int {
+	m := armVersionRe.FindStringSubmatch(cpuinfo)
+	if m == nil {
+		return 0
+	}
+	v, _ := strconv.Atoi(m[1])
+	// ARMv8 CPU running 32-bit userland
+	return min(v, 7)
 }
 
 func isIgnoredFile(name string) bool {
 	name = strings.ToLower(name)
-	return hashFileRe.MatchString(name) || ignoredFileRe.MatchString(name)
+	return hashFileRe.MatchString(name) || ignoredFileRe.MatchString(name) || signatureFileRe.MatchString(name)
 }
 
 func containsMusl(name string) bool {
 	return muslRe.MatchString(strings.ToLower(name))
 }
 
-func isMuslLibcPresent() bool {
-	matches, _ := filepath.Glob("/lib/libc.musl-*")
-	return len(matches) > 0
+// containsStatic reports whether the name labels a statically linked build, e.g., foo-linux-amd64-static.tar.gz.
+func containsStatic(name string) bool {
+	return slices.Contains(tokenize(name), "static")
 }
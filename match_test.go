@@ -1,6 +1,7 @@
 package main
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -14,6 +15,12 @@ func TestIsIgnoredFile(t *testing.T) {
 		{"checksums.txt", true},
 		{"checksum.json", true},
 		{"sha256sums.txt", true},
+		{"example_linux_amd64.tar.gz.sig", true},
+		{"example_linux_amd64.tar.gz.pem", true},
+		{"example_linux_amd64.tar.gz.bundle", true},
+		{"example_linux_amd64.tar.gz.sigstore.json", true},
+		{"example_linux_amd64.tar.gz.sigstore", true},
+		{"checksums.txt.asc", true},
 		{"sops-v3.9.0.linux.amd64", false},
 	}
 
@@ -43,3 +50,63 @@ func TestMuslRe(t *testing.T) {
 		}
 	}
 }
+
+func TestParseARMVersion(t *testing.T) {
+	tests := []struct {
+		cpuinfo  string
+		expected int
+	}{
+		{"processor\t: 0\nmodel name\t: ARMv7 Processor rev 4 (v7l)\nCPU architecture: 7\n", 7},
+		{"processor\t: 0\nmodel name\t: ARMv6-compatible processor rev 7 (v6l)\n", 6},
+		{"processor\t: 0\nCPU architecture: 8\n", 7},
+		{"processor\t: 0\n", 0},
+	}
+
+	for _, test := range tests {
+		result := parseARMVersion(test.cpuinfo)
+		if result != test.expected {
+			t.Errorf("For cpuinfo %q, expected %d, but got %d", test.cpuinfo, test.expected, result)
+		}
+	}
+}
+
+func TestFindOS(t *testing.T) {
+	tests := []struct {
+		filename string
+		expected []string
+	}{
+		{"jq-linux64", []string{"linux"}},
+		{"jq-linux32", []string{"linux"}},
+		{"jq-macos-amd64", []string{"darwin"}},
+		{"jq-win64.exe", []string{"windows"}},
+		{"tool_win32_x64.zip", []string{"windows"}},
+		{"tool_linux_amd64v3.tar.gz", []string{"linux"}},
+		{"linuxdeploy-x86_64.AppImage", nil},
+	}
+
+	for _, test := range tests {
+		if result := findOS(test.filename); !slices.Equal(result, test.expected) {
+			t.Errorf("For filename %s, expected %v, but got %v", test.filename, test.expected, result)
+		}
+	}
+}
+
+func TestFindArch(t *testing.T) {
+	tests := []struct {
+		filename string
+		expected []string
+	}{
+		{"jq-linux64", []string{"amd64"}},
+		{"jq-linux32", []string{"386"}},
+		{"tool_linux_amd64v3.tar.gz", []string{"amd64"}},
+		{"tool-x86_64v2-unknown-linux-gnu.tar.gz", []string{"amd64"}},
+		{"tool_linux_amd64_v1.tar.gz", []string{"amd64"}},
+		{"tool_linux_armv7.tar.gz", []string{"arm"}},
+	}
+
+	for _, test := range tests {
+		if result := findArch(test.filename); !slices.Equal(result, test.expected) {
+			t.Errorf("For filename %s, expected %v, but got %v", test.filename, test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// signature algorithms of minisign, ED signs the BLAKE2b-512 digest of the file
+	minisignAlgEd        = "Ed"
+	minisignAlgPrehashed = "ED"
+
+	trustedCommentPrefix = "trusted comment: "
+)
+
+// minisignPublicKey is the public key of minisign, i.e., base64 of the algorithm, the key ID and the Ed25519 key.
+type minisignPublicKey struct {
+	keyID [8]byte
+	key   ed25519.PublicKey
+}
+
+// minisignSignature is the .minisig file, the global signature signs the signature and the trusted comment.
+type minisignSignature struct {
+	alg            string
+	keyID          [8]byte
+	sig            []byte
+	trustedComment string
+	globalSig      []byte
+}
+
+// minisignVerifier verifies the .minisig signatures by the public key of the repo.
+type minisignVerifier struct {
+	key *minisignPublicKey
+}
+
+func (o installOptions) minisignVerifier(config *Config) (*minisignVerifier, error) {
+	s := cmp.Or(o.MinisignKey, config.Minisign.Keys[o.Repo])
+	if s == "" {
+		return nil, errors.New("minisign verification requires -minisign-key")
+	}
+	key, err := loadMinisignPublicKey(s)
+	if err != nil {
+		return nil, err
+	}
+	return &minisignVerifier{key: key}, nil
+}
+
+func (v *minisignVerifier) name() string {
+	return verifyMinisign
+}
+
+func (v *minisignVerifier) verify(release Release, target Asset, path, destDir string) (*Verification, error) {
+	a, ok := findSignatureAsset(release.Assets, target.Name, ".minisig")
+	if !ok {
+		return nil, nil
+	}
+	data, err := downloadSignature(release, a, destDir)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := parseMinisignSignature(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", a.Name, err)
+	}
+
+	if err := v.key.verify(sig, path); err != nil {
+		return nil, err
+	}
+	log.Printf("Trusted comment of %s: %s", a.Name, sig.trustedComment)
+	return &Verification{Signature: a.Name, Signer: fmt.Sprintf("%X", reverseBytes(sig.keyID[:]))}, nil
+}
+
+// verify verifies the signature of the file, and the global signature of the trusted comment.
+func (k *minisignPublicKey) verify(s *minisignSignature, path string) error {
+	if s.keyID != k.keyID {
+		return fmt.Errorf("%w: signed by key %X, expected %X", ErrInvalidSignature, reverseBytes(s.keyID[:]), reverseBytes(k.keyID[:]))
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var message []byte
+	if s.alg == minisignAlgPrehashed {
+		h, err := blake2b.New512(nil)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		message = h.Sum(nil)
+	} else if message, err = io.ReadAll(f); err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(k.key, message, s.sig) {
+		return ErrInvalidSignature
+	}
+	if !ed25519.Verify(k.key, append(bytes.Clone(s.sig), s.trustedComment...), s.globalSig) {
+		return fmt.Errorf("%w: invalid trusted comment", ErrInvalidSignature)
+	}
+	return nil
+}
+
+// loadMinisignPublicKey loads the public key of the base64 string, e.g., RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3,
+// or of the file, e.g., minisign.pub.
+func loadMinisignPublicKey(s string) (*minisignPublicKey, error) {
+	if key, err := parseMinisignPublicKey(s); err == nil {
+		return key, nil
+	}
+
+	data, err := os.ReadFile(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid minisign public key: %s", s)
+	}
+	lines := minisignLines(data)
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "untrusted comment:") {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("invalid minisign public key %s: no key", s)
+	}
+	key, err := parseMinisignPublicKey(lines[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid minisign public key %s: %w", s, err)
+	}
+	return key, nil
+}
+
+func parseMinisignPublicKey(s string) (*minisignPublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != 2+8+ed25519.PublicKeySize || string(data[:2]) != minisignAlgEd {
+		return nil, errors.New("unsupported public key")
+	}
+
+	k := &minisignPublicKey{key: ed25519.PublicKey(data[10:])}
+	copy(k.keyID[:], data[2:10])
+	return k, nil
+}
+
+func parseMinisignSignature(data []byte) (*minisignSignature, error) {
+	lines := minisignLines(data)
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], trustedCommentPrefix) {
+		return nil, errors.New("invalid minisign signature")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid minisign signature: %w", err)
+	}
+	if len(sig) != 2+8+ed25519.SignatureSize {
+		return nil, errors.New("invalid minisign signature")
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid minisign global signature: %w", err)
+	}
+	if len(globalSig) != ed25519.SignatureSize {
+		return nil, errors.New("invalid minisign global signature")
+	}
+
+	s := &minisignSignature{
+		alg:            string(sig[:2]),
+		sig:            sig[10:],
+		trustedComment: strings.TrimPrefix(lines[2], trustedCommentPrefix),
+		globalSig:      globalSig,
+	}
+	if s.alg != minisignAlgEd && s.alg != minisignAlgPrehashed {
+		return nil, fmt.Errorf("unsupported minisign signature algorithm: %s", s.alg)
+	}
+	copy(s.keyID[:], sig[2:10])
+	return s, nil
+}
+
+// minisignLines returns the non-empty lines without the trailing CR.
+func minisignLines(data []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// reverseBytes returns the key ID as minisign prints, which is little-endian.
+func reverseBytes(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[i] = b[len(b)-1-i]
+	}
+	return r
+}
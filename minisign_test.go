@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// minisignKey is a minisign key pair for tests.
+type minisignKey struct {
+	id   [8]byte
+	priv ed25519.PrivateKey
+	// base64 public key
+	pub string
+}
+
+func newMinisignKey(t *testing.T) *minisignKey {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := &minisignKey{priv: priv}
+	if _, err := rand.Read(k.id[:]); err != nil {
+		t.Fatal(err)
+	}
+	k.pub = base64.StdEncoding.EncodeToString(append(append([]byte(minisignAlgEd), k.id[:]...), pub...))
+	return k
+}
+
+// sign returns the .minisig of the content, the prehashed one signs the BLAKE2b-512 digest.
+func (k *minisignKey) sign(content string, prehashed bool, trustedComment string) string {
+	alg, message := minisignAlgEd, []byte(content)
+	if prehashed {
+		digest := blake2b.Sum512(message)
+		alg, message = minisignAlgPrehashed, digest[:]
+	}
+	sig := ed25519.Sign(k.priv, message)
+	globalSig := ed25519.Sign(k.priv, append(sig, trustedComment...))
+	return "untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte(alg), k.id[:]...), sig...)) + "\n" +
+		trustedCommentPrefix + trustedComment + "\n" +
+		base64.StdEncoding.EncodeToString(globalSig) + "\n"
+}
+
+func TestLoadMinisignPublicKey(t *testing.T) {
+	k := newMinisignKey(t)
+	path := filepath.Join(t.TempDir(), "minisign.pub")
+	if err := os.WriteFile(path, []byte("untrusted comment: minisign public key\r\n"+k.pub+"\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{k.pub, path} {
+		key, err := loadMinisignPublicKey(s)
+		if err != nil {
+			t.Errorf("loadMinisignPublicKey(%s) error = %v", s, err)
+			continue
+		}
+		if key.keyID != k.id {
+			t.Errorf("loadMinisignPublicKey(%s) key ID = %X, want %X", s, key.keyID, k.id)
+		}
+	}
+
+	for _, s := range []string{"", "RWQ=", filepath.Join(t.TempDir(), "missing.pub")} {
+		if _, err := loadMinisignPublicKey(s); err == nil {
+			t.Errorf("loadMinisignPublicKey(%s), want error", s)
+		}
+	}
+}
+
+func TestMinisignVerification(t *testing.T) {
+	k, other := newMinisignKey(t), newMinisignKey(t)
+	tests := []struct {
+		name    string
+		sig     string
+		wantErr error
+	}{
+		{"legacy", k.sign("artifact", false, "timestamp:1717000000\tfile:zig-linux-x86_64-0.13.0.tar.xz"), nil},
+		{"prehashed", k.sign("artifact", true, "timestamp:1717000000\tfile:zig-linux-x86_64-0.13.0.tar.xz\thashed"), nil},
+		{"tampered", k.sign("tampered", true, "timestamp:1717000000"), ErrInvalidSignature},
+		{"another key", other.sign("artifact", true, "timestamp:1717000000"), ErrInvalidSignature},
+		{
+			"forged trusted comment",
+			strings.Replace(k.sign("artifact", true, "timestamp:1717000000"), "timestamp:1717000000", "timestamp:1818000000", 1),
+			ErrInvalidSignature,
+		},
+		{"none", "", ErrNoSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := installOptions{packageOptions: packageOptions{MinisignKey: k.pub}}.minisignVerifier(&Config{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s := newAssetServer(t)
+			asset := s.write("zig-linux-x86_64-0.13.0.tar.xz", "artifact")
+			assets := []Asset{asset}
+			if tt.sig != "" {
+				assets = append(assets, s.write(asset.Name+".minisig", tt.sig))
+			}
+			if _, err := s.verify(v, assets, asset); !errors.Is(err, tt.wantErr) {
+				t.Errorf("verifyAsset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMinisignKeyOfRepo(t *testing.T) {
+	k := newMinisignKey(t)
+	config := &Config{Minisign: MinisignConfig{Keys: map[string]string{"ziglang/zig": k.pub}}}
+
+	v, err := installOptions{packageOptions: packageOptions{Repo: "ziglang/zig"}}.minisignVerifier(config)
+	if err != nil || v.key.keyID != k.id {
+		t.Errorf("minisignVerifier() = %v, %v, want the key of the repo", v, err)
+	}
+	if _, err := (installOptions{packageOptions: packageOptions{Repo: "other/repo"}}).minisignVerifier(config); err == nil {
+		t.Error("minisignVerifier() of repo without key, want error")
+	}
+
+	// -minisign-key implies -verify minisign
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	verifiers, err := installOptions{packageOptions: packageOptions{MinisignKey: k.pub}}.verifiers()
+	if err != nil || len(verifiers) != 1 || verifiers[0].name() != verifyMinisign {
+		t.Errorf("verifiers() = %v, %v, want minisign", verifiers, err)
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	packageDeb = "deb"
+	packageRPM = "rpm"
+	packageAPK = "apk"
+)
+
+var hostPackageFormat = detectPackageFormat("/etc/os-release")
+
+// distribution IDs in os-release and their package formats
+var distroPackageFormats = map[string]string{
+	"debian":    packageDeb,
+	"ubuntu":    packageDeb,
+	"rhel":      packageRPM,
+	"fedora":    packageRPM,
+	"centos":    packageRPM,
+	"suse":      packageRPM,
+	"opensuse":  packageRPM,
+	"amzn":      packageRPM,
+	"rocky":     packageRPM,
+	"almalinux": packageRPM,
+	"alpine":    packageAPK,
+}
+
+// tokens of the Android APKs, which are not Alpine packages, e.g., app-arm64-v8a-release.apk
+var androidAPKTokens = []string{"android", "armeabi", "v7a", "v8a"}
+
+var (
+	rpmLeadMagic   = []byte{0xed, 0xab, 0xee, 0xdb}
+	rpmHeaderMagic = []byte{0x8e, 0xad, 0xe8, 0x01}
+)
+
+// packageFormat returns the package format by the name, e.g., deb for foo_1.0.0_amd64.deb.
+func packageFormat(name string) string {
+	switch ext := strings.TrimPrefix(strings.ToLower(path.Ext(name)), "."); ext {
+	case packageDeb, packageRPM:
+		return ext
+	case packageAPK:
+		if !isAndroidAPK(name) {
+			return ext
+		}
+	}
+	return ""
+}
+
+func isAndroidAPK(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".apk") && slices.ContainsFunc(tokenize(name), func(token string) bool {
+		return slices.Contains(androidAPKTokens, token)
+	})
+}
+
+func isPackage(name string) bool {
+	return packageFormat(name) != ""
+}
+
+// detectPackageFormat returns the package format of the host distribution by ID and ID_LIKE of os-release.
+func detectPackageFormat(osRelease string) string {
+	data, err := os.ReadFile(osRelease)
+	if err != nil {
+		return ""
+	}
+	return parsePackageFormat(string(data))
+}
+
+func parsePackageFormat(osRelease string) string {
+	var ids []string
+	scanner := bufio.NewScanner(strings.NewReader(osRelease))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok && (key == "ID" || key == "ID_LIKE") {
+			ids = append(ids, strings.Fields(strings.Trim(value, `"'`))...)
+		}
+	}
+
+	for _, id := range ids {
+		if format, ok := distroPackageFormats[id]; ok {
+			return format
+		}
+	}
+	return ""
+}
+
+// isPackageBinary reports whether the file in the package is in a bin or sbin directory,
+// other executables, e.g., shared libraries and maintainer scripts, are not installed.
+func isPackageBinary(name string) bool {
+	dirs := strings.Split(path.Dir(strings.TrimPrefix(name, "./")), "/")
+	return slices.Contains(dirs, "bin") || slices.Contains(dirs, "sbin")
+}
+
+// walkPackage calls fn with the binaries in the deb, rpm or apk package.
+func walkPackage(packagePath, format string, fn walkFunc) error {
+	file, err := os.Open(packagePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	binaries := func(name string, r io.Reader, mode os.FileMode) error {
+		if !isPackageBinary(name) {
+			return nil
+		}
+		return fn(name, r, mode)
+	}
+
+	switch format {
+	case packageDeb:
+		return walkDeb(file, binaries)
+	case packageRPM:
+		return walkRPM(file, binaries)
+	case packageAPK:
+		return walkAPK(file, binaries)
+	default:
+		return fmt.Errorf("unsupported package: %s", path.Base(packagePath))
+	}
+}
+
+// walkDeb walks data.tar.* in the ar archive.
+func walkDeb(r io.Reader, fn walkFunc) error {
+	br := bufio.NewReader(r)
+	magic := make([]byte, 8)
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != "!<arch>\n" {
+		return errors.New("invalid deb package")
+	}
+
+	header := make([]byte, 60)
+	for {
+		if _, err := io.ReadFull(br, header); err != nil {
+			if err == io.EOF {
+				return errors.New("no data.tar in deb package")
+			}
+			return err
+		}
+
+		name := strings.TrimSuffix(strings.TrimSpace(string(header[:16])), "/")
+		size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid deb package: %w", err)
+		}
+
+		if strings.HasPrefix(name, "data.tar") {
+			compression, ok := tarCompression(name)
+			if !ok {
+				return fmt.Errorf("unsupported deb data: %s", name)
+			}
+			rc, err := newDecompressor(io.LimitReader(br, size), compression)
+			if err != nil {
+				return err
+			}
+			defer rc.Close()
+			return walkTar(rc, fn)
+		}
+
+		// data is padded to even
+		if _, err := br.Discard(int(size + size%2)); err != nil {
+			return err
+		}
+	}
+}
+
+// walkRPM walks the cpio payload after the lead, signature header and header.
+func walkRPM(r io.Reader, fn walkFunc) error {
+	br := bufio.NewReader(r)
+	lead := make([]byte, 96)
+	if _, err := io.ReadFull(br, lead); err != nil || !bytes.HasPrefix(lead, rpmLeadMagic) {
+		return errors.New("invalid rpm package")
+	}
+
+	// signature header is padded to 8 bytes
+	size, err := skipRPMHeader(br)
+	if err != nil {
+		return err
+	}
+	if pad := (8 - size%8) % 8; pad > 0 {
+		if _, err := br.Discard(pad); err != nil {
+			return err
+		}
+	}
+	if _, err := skipRPMHeader(br); err != nil {
+		return err
+	}
+
+	// the payload compression is detected by magic bytes
+	magic, _ := br.Peek(6)
+	rc, err := newDecompressor(br, magicCompression(magic))
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return walkCpio(rc, fn)
+}
+
+// skipRPMHeader skips the header structure, and returns its size.
+func skipRPMHeader(br *bufio.Reader) (int, error) {
+	intro := make([]byte, 16)
+	if _, err := io.ReadFull(br, intro); err != nil || !bytes.HasPrefix(intro, rpmHeaderMagic) {
+		return 0, errors.New("invalid rpm header")
+	}
+	nindex := binary.BigEndian.Uint32(intro[8:12])
+	hsize := binary.BigEndian.Uint32(intro[12:16])
+	size := int(16*nindex + hsize)
+	if _, err := br.Discard(size); err != nil {
+		return 0, err
+	}
+	return 16 + size, nil
+}
+
+// walkCpio walks the cpio archive in the SVR4 (newc) format.
+func walkCpio(r io.Reader, fn walkFunc) error {
+	br := bufio.NewReader(r)
+	header := make([]byte, 110)
+	for {
+		if _, err := io.ReadFull(br, header); err != nil {
+			return fmt.Errorf("invalid cpio archive: %w", err)
+		}
+		if magic := string(header[:6]); magic != "070701" && magic != "070702" {
+			return fmt.Errorf("unsupported cpio format: %q", magic)
+		}
+
+		field := func(i int) (int64, error) {
+			return strconv.ParseInt(string(header[6+8*i:14+8*i]), 16, 64)
+		}
+		mode, err := field(1)
+		if err != nil {
+			return err
+		}
+		fileSize, err := field(6)
+		if err != nil {
+			return err
+		}
+		nameSize, err := field(11)
+		if err != nil {
+			return err
+		}
+
+		// header and name are padded to 4 bytes
+		nameBuf := make([]byte, nameSize+(4-(110+nameSize)%4)%4)
+		if _, err := io.ReadFull(br, nameBuf); err != nil {
+			return err
+		}
+		name := string(bytes.TrimRight(nameBuf[:nameSize], "\x00"))
+		if name == "TRAILER!!!" {
+			return nil
+		}
+
+		data := io.LimitReader(br, fileSize)
+		// regular executable file
+		if mode&0170000 == 0100000 && mode&0111 != 0 {
+			if err := fn(name, data, os.FileMode(mode&0777)); err != nil {
+				return err
+			}
+		}
+		if _, err := io.Copy(io.Discard, data); err != nil {
+			return err
+		}
+		if _, err := br.Discard(int((4 - fileSize%4) % 4)); err != nil {
+			return err
+		}
+	}
+}
+
+// walkAPK walks the concatenated gzip tarballs of the Alpine package, i.e., signature, control and data.
+func walkAPK(r io.Reader, fn walkFunc) error {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("invalid apk package: %w", err)
+	}
+	defer gzr.Close()
+
+	// skip .PKGINFO, .SIGN.*, .pre-install, etc.
+	return walkTar(gzr, func(name string, r io.Reader, mode os.FileMode) error {
+		if strings.HasPrefix(path.Base(name), ".") {
+			return nil
+		}
+		return fn(name, r, mode)
+	})
+}
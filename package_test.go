@@ -0,0 +1,310 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ulikunitz/xz"
+)
+
+// test package files, only usr/bin/example is installed
+var testPackageFiles = []struct {
+	name string
+	mode int64
+}{
+	{"./usr/bin/example", 0755},
+	{"./usr/lib/example/libexample.so", 0755},
+	{"./usr/share/doc/example/README", 0644},
+}
+
+func writeTestPackageTar(t *testing.T, w io.Writer, close bool) {
+	t.Helper()
+	tw := tar.NewWriter(w)
+	for _, f := range testPackageFiles {
+		body := []byte("content of " + f.name)
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: f.mode, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if close {
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	} else if err := tw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTestDeb(t *testing.T, w io.Writer) {
+	t.Helper()
+	var data bytes.Buffer
+	xw, err := xz.NewWriter(&data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestPackageTar(t, xw, true)
+	xw.Close()
+
+	io.WriteString(w, "!<arch>\n")
+	for _, member := range []struct {
+		name string
+		data []byte
+	}{
+		{"debian-binary", []byte("2.0\n")},
+		{"control.tar.xz", []byte("control")},
+		{"data.tar.xz", data.Bytes()},
+	} {
+		fmt.Fprintf(w, "%-16s%-12d%-6d%-6d%-8s%-10d`\n", member.name, 0, 0, 0, "100644", len(member.data))
+		w.Write(member.data)
+		if len(member.data)%2 == 1 {
+			io.WriteString(w, "\n")
+		}
+	}
+}
+
+func writeTestRPM(t *testing.T, w io.Writer) {
+	t.Helper()
+	lead := make([]byte, 96)
+	copy(lead, rpmLeadMagic)
+	w.Write(lead)
+
+	// signature header of 1 index and 5 bytes data padded to 8 bytes, and the header
+	for _, hsize := range []uint32{5, 8} {
+		header := make([]byte, 16)
+		copy(header, rpmHeaderMagic)
+		binary.BigEndian.PutUint32(header[8:], 1)
+		binary.BigEndian.PutUint32(header[12:], hsize)
+		w.Write(header)
+		w.Write(make([]byte, 16+hsize))
+		if hsize == 5 {
+			w.Write(make([]byte, 3))
+		}
+	}
+
+	gw := gzip.NewWriter(w)
+	writeCpio := func(name string, mode int64, data []byte) {
+		fmt.Fprintf(gw, "070701%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X",
+			0, mode, 0, 0, 1, 0, len(data), 0, 0, 0, 0, len(name)+1, 0)
+		io.WriteString(gw, name+"\x00")
+		gw.Write(make([]byte, (4-(110+len(name)+1)%4)%4))
+		gw.Write(data)
+		gw.Write(make([]byte, (4-len(data)%4)%4))
+	}
+	for _, f := range testPackageFiles {
+		writeCpio(f.name, 0100000|f.mode, []byte("content of "+f.name))
+	}
+	writeCpio("TRAILER!!!", 0, nil)
+	gw.Close()
+}
+
+func writeTestAPK(t *testing.T, w io.Writer) {
+	t.Helper()
+	// signature and control segments are cut without the end-of-archive blocks
+	for _, name := range []string{".SIGN.RSA.example.rsa.pub", ".PKGINFO"} {
+		gw := gzip.NewWriter(w)
+		tw := tar.NewWriter(gw)
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: 4, Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte("data"))
+		tw.Flush()
+		gw.Close()
+	}
+
+	gw := gzip.NewWriter(w)
+	writeTestPackageTar(t, gw, true)
+	gw.Close()
+}
+
+func TestWalkPackage(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(*testing.T, io.Writer)
+	}{
+		{"example_1.0.0_amd64.deb", writeTestDeb},
+		{"example-1.0.0-1.x86_64.rpm", writeTestRPM},
+		{"example-1.0.0-r0.apk", writeTestAPK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.name)
+			f, err := os.Create(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.write(t, f)
+			f.Close()
+
+			content, compression, err := sniffContent(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names, contents []string
+			err = walkArchive(path, content, compression, func(name string, r io.Reader, mode os.FileMode) error {
+				data, err := io.ReadAll(r)
+				names = append(names, name)
+				contents = append(contents, string(data))
+				return err
+			})
+			if err != nil {
+				t.Fatalf("walkArchive() error = %v", err)
+			}
+			if want := []string{"./usr/bin/example"}; !slices.Equal(names, want) {
+				t.Errorf("walkArchive() = %v, want %v", names, want)
+			}
+			if want := []string{"content of ./usr/bin/example"}; !slices.Equal(contents, want) {
+				t.Errorf("walkArchive() contents = %q, want %q", contents, want)
+			}
+		})
+	}
+}
+
+func TestIsPackageBinary(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"./usr/bin/example", true},
+		{"usr/sbin/example", true},
+		{"./opt/example/bin/example", true},
+		{"./usr/lib/example/libexample.so", false},
+		{"./usr/libexec/example/helper", false},
+		{"./bin", false},
+	}
+
+	for _, test := range tests {
+		if result := isPackageBinary(test.name); result != test.expected {
+			t.Errorf("isPackageBinary(%s) = %v; expected %v", test.name, result, test.expected)
+		}
+	}
+}
+
+func TestParsePackageFormat(t *testing.T) {
+	tests := []struct {
+		name      string
+		osRelease string
+		expected  string
+	}{
+		{"debian", "PRETTY_NAME=\"Debian GNU/Linux 12 (bookworm)\"\nID=debian\n", packageDeb},
+		{"ubuntu", "ID=ubuntu\nID_LIKE=debian\n", packageDeb},
+		{"linuxmint", "ID=linuxmint\nID_LIKE=\"ubuntu debian\"\n", packageDeb},
+		{"rocky", "ID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\n", packageRPM},
+		{"opensuse", "ID=\"opensuse-tumbleweed\"\nID_LIKE=\"opensuse suse\"\n", packageRPM},
+		{"alpine", "ID=alpine\n", packageAPK},
+		{"arch", "ID=arch\n", ""},
+	}
+
+	for _, tt := range tests {
+		if result := parsePackageFormat(tt.osRelease); result != tt.expected {
+			t.Errorf("parsePackageFormat(%s) = %q; expected %q", tt.name, result, tt.expected)
+		}
+	}
+}
+
+func TestFindMaxWeightPackage(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the package formats are of linux distributions")
+	}
+	defer func(format string) { hostPackageFormat = format }(hostPackageFormat)
+	host := Platform{"linux", runtime.GOARCH, hostLibc()}
+
+	tests := []struct {
+		name       string
+		hostFormat string
+		target     Platform
+		assets     []string
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "archive over package",
+			hostFormat: packageDeb,
+			target:     host,
+			assets:     []string{"example_1.0.0_linux_ARCH.tar.gz", "example_1.0.0_ARCH.deb", "example-1.0.0-1.ARCH.rpm"},
+			want:       "example_1.0.0_linux_ARCH.tar.gz",
+		},
+		{
+			name:       "binary over host package",
+			hostFormat: packageDeb,
+			target:     host,
+			assets:     []string{"example_1.0.0_ARCH.deb", "example-1.0.0-1.ARCH.rpm", "example_1.0.0_linux_ARCH"},
+			want:       "example_1.0.0_linux_ARCH",
+		},
+		{
+			name:       "host package of packages",
+			hostFormat: packageDeb,
+			target:     host,
+			assets:     []string{"example_1.0.0_ARCH.deb", "example-1.0.0-1.ARCH.rpm"},
+			want:       "example_1.0.0_ARCH.deb",
+		},
+		{
+			name:       "host package of arch",
+			hostFormat: packageRPM,
+			target:     host,
+			assets:     []string{"example-1.0.0-1.s390x.rpm", "example-1.0.0-1.ARCH.rpm"},
+			want:       "example-1.0.0-1.ARCH.rpm",
+		},
+		{
+			name:       "binary over other package",
+			hostFormat: packageRPM,
+			target:     host,
+			assets:     []string{"example-linux-ARCH", "example_1.0.0_ARCH.deb"},
+			want:       "example-linux-ARCH",
+		},
+		{
+			name:       "other packages",
+			hostFormat: "",
+			target:     host,
+			assets:     []string{"example_1.0.0_ARCH.deb", "example-1.0.0-1.ARCH.rpm"},
+			wantErr:    ErrMultipleMaxWeightAsset,
+		},
+		{
+			name:       "cross-platform packages",
+			hostFormat: packageDeb,
+			target:     Platform{"linux", "s390x", libcGNU},
+			assets:     []string{"example_1.0.0_s390x.deb", "example-1.0.0-1.s390x.rpm"},
+			wantErr:    ErrMultipleMaxWeightAsset,
+		},
+		{
+			name:       "android apk",
+			hostFormat: packageAPK,
+			target:     Platform{"linux", "arm64", libcMusl},
+			assets:     []string{"example-arm64-v8a-release.apk", "example-1.0.0-r0-arm64.apk"},
+			want:       "example-1.0.0-r0-arm64.apk",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hostPackageFormat = tt.hostFormat
+			var assets Assets
+			// the assets of ARCH are of the host arch, e.g., example_1.0.0_amd64.deb
+			for _, name := range tt.assets {
+				assets = append(assets, *newAsset(strings.ReplaceAll(name, "ARCH", runtime.GOARCH), "", tt.target))
+			}
+
+			got, err := assets.FindMaxWeightAsset()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FindMaxWeightAsset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if want := strings.ReplaceAll(tt.want, "ARCH", runtime.GOARCH); got.Name != want {
+				t.Errorf("FindMaxWeightAsset() = %s, want %s", got.Name, want)
+			}
+		})
+	}
+}
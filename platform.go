@@ -0,0 +1,161 @@
+package main
+
+import (
+	"debug/elf"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	libcAuto = "auto"
+	libcGNU  = "gnu"
+	libcMusl = "musl"
+)
+
+// Platform is the target of the install, the arch may include the ARM variant, e.g., armv7,
+// and the libc is gnu or musl on linux.
+type Platform struct {
+	OS   string
+	Arch string
+	Libc string
+}
+
+func hostPlatform() Platform {
+	p := Platform{OS: runtime.GOOS, Arch: hostArch()}
+	if p.OS == "linux" {
+		p.Libc = hostLibc()
+	}
+	return p
+}
+
+func (p Platform) isMusl() bool {
+	return p.Libc == libcMusl
+}
+
+// isHost reports whether the binaries of the platform run on the host, regardless of the ARM variant,
+// the dynamic binaries of another libc do not, e.g., musl on a glibc host.
+func (p Platform) isHost() bool {
+	arch, _ := parseArch(p.Arch)
+	return p.OS == runtime.GOOS && arch == runtime.GOARCH && (p.OS != "linux" || p.Libc == hostLibc())
+}
+
+func (p Platform) String() string {
+	if p.Libc == "" {
+		return p.OS + "/" + p.Arch
+	}
+	return p.OS + "/" + p.Arch + "/" + p.Libc
+}
+
+// hostLibc returns the libc of the host, gnu or musl.
+var hostLibc = sync.OnceValue(detectLibc)
+
+// detectLibc detects the libc by the interpreter of the system binaries, or ldd --version
+// if they are static, e.g., busybox, glibc is assumed if neither tells.
+func detectLibc() string {
+	for _, path := range []string{"/bin/sh", "/usr/bin/env", "/bin/ls"} {
+		f, err := elf.Open(path)
+		if err != nil {
+			continue
+		}
+		interp, err := elfInterpreter(f)
+		f.Close()
+		if err != nil {
+			continue
+		}
+		if libc := interpreterLibc(interp); libc != "" {
+			return libc
+		}
+	}
+
+	// musl prints its version to stderr
+	out, _ := exec.Command("ldd", "--version").CombinedOutput()
+	return parseLddLibc(string(out))
+}
+
+// interpreterLibc returns the libc of the interpreter, e.g., musl for /lib/ld-musl-x86_64.so.1.
+func interpreterLibc(interp string) string {
+	switch {
+	case strings.Contains(interp, "ld-musl"):
+		return libcMusl
+	case strings.Contains(interp, "ld-linux"), strings.Contains(interp, "ld64.so"):
+		return libcGNU
+	}
+	return ""
+}
+
+func parseLddLibc(out string) string {
+	if strings.Contains(strings.ToLower(out), "musl") {
+		return libcMusl
+	}
+	return libcGNU
+}
+
+// normalizeOS returns GOOS of the OS alias, e.g., darwin for macos.
+func normalizeOS(goos string) (string, bool) {
+	goos = strings.ToLower(goos)
+	if knownOS[goos] {
+		return goos, true
+	}
+	for os, aliases := range knownOSAliases {
+		if slices.Contains(aliases, goos) {
+			return os, true
+		}
+	}
+	return "", false
+}
+
+// normalizeArch returns GOARCH of the arch alias, e.g., amd64 for x86_64, the ARM variant is kept, e.g., armv7 for armv7l.
+func normalizeArch(goarch string) (string, bool) {
+	goarch = strings.ToLower(goarch)
+	if knownArch[goarch] {
+		return goarch, true
+	}
+	if variant, ok := knownARMVariants[goarch]; ok {
+		return fmt.Sprintf("armv%d", variant), true
+	}
+	for arch, aliases := range knownArchAliases {
+		if slices.Contains(aliases, goarch) {
+			return arch, true
+		}
+	}
+	return "", false
+}
+
+// platform returns the target platform, which defaults to the host, the libc of the host
+// is only detected for the host OS, and other linux targets default to gnu.
+func (o installOptions) platform() (Platform, error) {
+	p := hostPlatform()
+	if o.OS != "" {
+		goos, ok := normalizeOS(o.OS)
+		if !ok {
+			return Platform{}, fmt.Errorf("invalid os: %s", o.OS)
+		}
+		p.OS = goos
+	}
+	if o.Arch != "" {
+		goarch, ok := normalizeArch(o.Arch)
+		if !ok {
+			return Platform{}, fmt.Errorf("invalid arch: %s", o.Arch)
+		}
+		p.Arch = goarch
+	}
+
+	switch o.Libc {
+	case "", libcAuto:
+		if p.OS != runtime.GOOS {
+			p.Libc = libcGNU
+		}
+	case libcGNU, libcMusl:
+		p.Libc = o.Libc
+	default:
+		return Platform{}, fmt.Errorf("invalid libc: %s, options: gnu, musl, auto", o.Libc)
+	}
+	if p.OS != "linux" {
+		p.Libc = ""
+	}
+	return p, nil
+}
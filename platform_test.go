@@ -0,0 +1,92 @@
+package main
+
+import "testing"
+
+func TestInterpreterLibc(t *testing.T) {
+	tests := []struct {
+		interp   string
+		expected string
+	}{
+		{"/lib64/ld-linux-x86-64.so.2", libcGNU},
+		{"/lib/ld-linux-aarch64.so.1", libcGNU},
+		{"/lib/ld-linux-armhf.so.3", libcGNU},
+		{"/lib64/ld64.so.2", libcGNU},
+		{"/lib/ld-musl-x86_64.so.1", libcMusl},
+		{"/lib/ld-musl-armhf.so.1", libcMusl},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if result := interpreterLibc(test.interp); result != test.expected {
+			t.Errorf("interpreterLibc(%s) = %s; expected %s", test.interp, result, test.expected)
+		}
+	}
+}
+
+func TestParseLddLibc(t *testing.T) {
+	tests := []struct {
+		out      string
+		expected string
+	}{
+		{"ldd (Debian GLIBC 2.36-9+deb12u4) 2.36\n", libcGNU},
+		{"musl libc (x86_64)\nVersion 1.2.4\nDynamic Program Loader\n", libcMusl},
+		{"", libcGNU},
+	}
+
+	for _, test := range tests {
+		if result := parseLddLibc(test.out); result != test.expected {
+			t.Errorf("parseLddLibc(%q) = %s; expected %s", test.out, result, test.expected)
+		}
+	}
+}
+
+func TestPlatformOptions(t *testing.T) {
+	tests := []struct {
+		opts    installOptions
+		want    Platform
+		wantErr bool
+	}{
+		{installOptions{packageOptions: packageOptions{OS: "linux", Arch: "arm64", Libc: libcMusl}}, Platform{"linux", "arm64", libcMusl}, false},
+		{installOptions{packageOptions: packageOptions{OS: "linux", Arch: "x86_64", Libc: libcGNU}}, Platform{"linux", "amd64", libcGNU}, false},
+		{installOptions{packageOptions: packageOptions{OS: "Linux", Arch: "armhf", Libc: libcGNU}}, Platform{"linux", "armv6", libcGNU}, false},
+		{installOptions{packageOptions: packageOptions{OS: "macos", Arch: "aarch64", Libc: libcMusl}}, Platform{"darwin", "arm64", ""}, false},
+		{installOptions{packageOptions: packageOptions{OS: "windows", Arch: "amd64"}}, Platform{"windows", "amd64", ""}, false},
+		{installOptions{packageOptions: packageOptions{OS: "beos"}}, Platform{}, true},
+		{installOptions{packageOptions: packageOptions{Arch: "z80"}}, Platform{}, true},
+		{installOptions{packageOptions: packageOptions{Libc: "uclibc"}}, Platform{}, true},
+	}
+
+	for _, tt := range tests {
+		p, err := tt.opts.platform()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("platform() with %+v error = %v, wantErr %v", tt.opts, err, tt.wantErr)
+			continue
+		}
+		if p != tt.want {
+			t.Errorf("platform() with %+v = %v, want %v", tt.opts, p, tt.want)
+		}
+	}
+}
+
+func TestPlatformIsHost(t *testing.T) {
+	host := hostPlatform()
+	otherLibc, otherArch := host, host
+	otherLibc.Libc = libcMusl
+	if host.Libc == libcMusl {
+		otherLibc.Libc = libcGNU
+	}
+	otherArch.Arch = "s390x"
+	if host.Arch == "s390x" {
+		otherArch.Arch = "amd64"
+	}
+
+	if !host.isHost() {
+		t.Errorf("%s isHost() = false, want true", host)
+	}
+	if host.OS == "linux" && otherLibc.isHost() {
+		t.Errorf("%s isHost() = true, want false", otherLibc)
+	}
+	if otherArch.isHost() {
+		t.Errorf("%s isHost() = true, want false", otherArch)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
-	"runtime"
 	"slices"
 	"strings"
 )
@@ -27,16 +26,42 @@ type Asset struct {
 	matchesArch            bool
 	supportedArchiveFormat bool
 	libc                   int
+	matchesARMVariant      bool
+	// built for a newer ARM variant than the target
+	incompatible bool
+	// deb, rpm or apk package of the host distribution, which breaks the tie of packages
+	matchesPackageFormat bool
+	// static build on the host of old glibc
+	preferredStatic bool
+	// sum of the weights of the matched scoring rules, and the rules
+	ruleWeight   int
+	matchedRules string
 }
 
+// NewAsset scores the asset for the host, which is scored again for the target at selection.
 func NewAsset(name, url string) *Asset {
-	return newAsset(name, url, runtime.GOOS, runtime.GOARCH, isMusl)
+	return newAsset(name, url, hostPlatform())
 }
 
-func newAsset(name, url, goos, goarch string, isMusl bool) *Asset {
-	var libc int
+// newAsset scores the asset for the target platform.
+func newAsset(name, url string, p Platform) *Asset {
+	var (
+		libc              int
+		matchesARMVariant bool
+		incompatible      bool
+	)
 
-	if !isMusl {
+	goos := p.OS
+	goarch, armVariant := parseArch(p.Arch)
+	if goarch == "arm" && armVariant > 0 && matchesArch(name, goarch) {
+		if variant := findARMVariant(name); variant == armVariant {
+			matchesARMVariant = true
+		} else if variant > armVariant {
+			incompatible = true
+		}
+	}
+
+	if !p.isMusl() {
 		if !containsMusl(name) {
 			libc = 1
 		} else {
@@ -50,7 +75,7 @@ func newAsset(name, url, goos, goarch string, isMusl bool) *Asset {
 		}
 	}
 
-	return &Asset{
+	asset := &Asset{
 		Name:                   name,
 		URL:                    url,
 		containsOS:             containsOS(name),
@@ -59,7 +84,30 @@ func newAsset(name, url, goos, goarch string, isMusl bool) *Asset {
 		matchesArch:            matchesArch(name, goarch),
 		supportedArchiveFormat: isSupportedArchiveFormat(name),
 		libc:                   libc,
+		matchesARMVariant:      matchesARMVariant,
+		incompatible:           incompatible,
+	}
+
+	// packages are built for linux, which is usually omitted in the name, e.g., foo_1.0.0_amd64.deb
+	if format := packageFormat(name); format != "" {
+		if !asset.containsOS {
+			asset.containsOS = true
+			asset.matchesOS = goos == "linux"
+		}
+		// the host distribution is irrelevant to other targets
+		asset.matchesPackageFormat = p.isHost() && format == hostPackageFormat
+	}
+	if isAndroidAPK(name) {
+		asset.containsOS = true
+		asset.matchesOS = goos == "android"
 	}
+
+	// the glibc version is of the host
+	if goos == "linux" && !p.isMusl() && p.isHost() && containsStatic(name) {
+		asset.preferredStatic = isOldGlibc(hostGlibcVersion())
+	}
+
+	return asset
 }
 
 func (a Asset) Weight() int {
@@ -70,42 +118,105 @@ func (a Asset) Weight() int {
 		a.matchesOS,
 		a.matchesArch,
 		a.supportedArchiveFormat,
+		a.matchesARMVariant,
+		a.preferredStatic,
 	} {
 		sum += boolToInt(b)
 	}
-	sum += a.libc
+	sum += a.libc + a.ruleWeight
 	return sum
 }
 
+// applyRules adjusts the weight by the matched scoring rules.
+func (a *Asset) applyRules(rules []ScoringRule) {
+	var matched []string
+	for _, r := range rules {
+		if r.matches(a.Name) {
+			a.ruleWeight += r.Weight
+			matched = append(matched, r.String())
+		}
+	}
+	a.matchedRules = strings.Join(matched, ", ")
+}
+
 type Assets []Asset
 
 func (as Assets) FindMaxWeightAsset() (Asset, error) {
+	asset, _, err := as.findMaxWeightAsset()
+	return asset, err
+}
+
+// findMaxWeightAsset returns the asset of the max weight, and the reason why it wins.
+func (as Assets) findMaxWeightAsset() (Asset, string, error) {
+	as = slices.DeleteFunc(slices.Clone(as), func(a Asset) bool {
+		return a.incompatible
+	})
 	if len(as) == 0 {
-		return Asset{}, ErrNoAsset
+		return Asset{}, "", ErrNoAsset
 	}
-	// desc
+	// desc, and by name to list the assets of the same weight in the same order
 	slices.SortStableFunc(as, func(a, b Asset) int {
-		return cmp.Compare(b.Weight(), a.Weight())
+		return cmp.Or(cmp.Compare(b.Weight(), a.Weight()), strings.Compare(a.Name, b.Name))
 	})
 
 	maxWeight := as[0].Weight()
-	for i := 1; i < len(as); i++ {
-		if as[i].Weight() == maxWeight {
-			var maxAs Assets
-			for _, v := range as {
-				if v.Weight() == maxWeight {
-					maxAs = append(maxAs, v)
-				} else {
-					break
-				}
-			}
-			return Asset{}, fmt.Errorf("%w: %s", ErrMultipleMaxWeightAsset, maxAs.JoinNameWithWeight())
+	var maxAs, others Assets
+	for _, a := range as {
+		if a.Weight() == maxWeight {
+			maxAs = append(maxAs, a)
 		} else {
-			break
+			others = append(others, a)
 		}
 	}
 
-	return as[0], nil
+	reason := fmt.Sprintf("the highest weight %d", maxWeight)
+	if maxAs[0].matchedRules != "" && len(maxAs) == 1 {
+		reason += fmt.Sprintf(" with rules %s", maxAs[0].matchedRules)
+	}
+	// packages are built for linux, so prefer the assets of the target OS to archives of another OS
+	targetOS := slices.DeleteFunc(slices.Clone(maxAs), func(a Asset) bool {
+		return !a.matchesOS
+	})
+	if len(targetOS) > 0 && len(targetOS) < len(maxAs) {
+		reason += ", built for the target OS"
+		maxAs = targetOS
+	}
+	// prefer archives and binaries to packages
+	archives := slices.DeleteFunc(slices.Clone(maxAs), func(a Asset) bool {
+		return isPackage(a.Name)
+	})
+	if len(archives) > 0 && len(archives) < len(maxAs) {
+		packages := slices.DeleteFunc(maxAs, func(a Asset) bool {
+			return !isPackage(a.Name)
+		})
+		reason += fmt.Sprintf(", preferred to the packages %s", packages.JoinName())
+		maxAs = archives
+	}
+	// the static build is preferred by weight on old glibc, otherwise it is the fallback
+	// if the glibc version of the host is not satisfied
+	dynamic := slices.DeleteFunc(slices.Clone(maxAs), func(a Asset) bool {
+		return containsStatic(a.Name)
+	})
+	if len(dynamic) > 0 && len(dynamic) < len(maxAs) {
+		reason += ", preferred to the static build"
+		maxAs = dynamic
+	}
+	// the package of the host distribution only if packages are the only assets
+	hostPackages := slices.DeleteFunc(slices.Clone(maxAs), func(a Asset) bool {
+		return !a.matchesPackageFormat
+	})
+	if len(hostPackages) > 0 && len(hostPackages) < len(maxAs) {
+		reason += ", the package of the host distribution"
+		maxAs = hostPackages
+	}
+	if len(maxAs) > 1 {
+		return Asset{}, "", fmt.Errorf("%w: %s, use -pattern or -rule to break the tie", ErrMultipleMaxWeightAsset, maxAs.JoinNameWithWeight())
+	}
+	if len(others) > 0 {
+		reason += fmt.Sprintf(", the next is %s of %d", others[0].Name, others[0].Weight())
+	}
+
+	return maxAs[0], reason, nil
 }
 
 func (as Assets) JoinName() string {
@@ -129,16 +240,31 @@ func (as Assets) joinName(withWeight bool) string {
 }
 
 type Release struct {
-	Name         string
-	TagName      string
+	Name       string
+	TagName    string
+	Prerelease bool
+	// draft or upcoming release, which is not published yet
+	Draft        bool
 	Assets       []Asset
 	AuthHeaders  map[string]string
 	AssetPattern *regexp.Regexp
+	// exact asset name rendered from the asset template
+	AssetName    string
+	ScoringRules []ScoringRule
+	// signature verifications of the downloaded asset
+	Verifiers []verifier
+}
+
+// IsPrerelease reports whether the release is marked as a pre-release, or its tag is a pre-release version.
+func (r Release) IsPrerelease() bool {
+	return r.Prerelease || (isVersion(r.TagName) && isPreRelease(r.TagName))
 }
 
 type RepoProvider interface {
 	GetLatestRelease() (Release, error)
 	GetTaggedRelease(tag string) (Release, error)
+	// ListReleases returns all releases, the assets may be omitted, use GetTaggedRelease for them.
+	ListReleases() ([]Release, error)
 }
 
 func GetRelease(url string, headers map[string]string, target interface{}) error {
@@ -161,3 +287,21 @@ func GetRelease(url string, headers map[string]string, target interface{}) error
 
 	return nil
 }
+
+// listPagedReleases fetches the pages of releases until a page is not full.
+func listPagedReleases[T any](pageURL func(page int) string, perPage int, headers map[string]string, convert func(T) Release) ([]Release, error) {
+	var releases []Release
+	for page := 1; ; page++ {
+		var items []T
+		if err := GetRelease(pageURL(page), headers, &items); err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			releases = append(releases, convert(item))
+		}
+		if len(items) < perPage {
+			break
+		}
+	}
+	return releases, nil
+}
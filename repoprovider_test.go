@@ -2,6 +2,10 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
 	"testing"
 )
 
@@ -13,52 +17,97 @@ func TestWeight(t *testing.T) {
 	}{
 		{
 			name:   "node_exporter-1.8.2.linux-arm64.tar.gz",
-			asset:  newAsset("node_exporter-1.8.2.linux-arm64.tar.gz", "", "linux", "arm64", false),
+			asset:  newAsset("node_exporter-1.8.2.linux-arm64.tar.gz", "", Platform{"linux", "arm64", libcGNU}),
 			weight: 6,
 		},
 		{
 			name:   "aerospike-prometheus-exporter_1.17.0_x86_64.tgz",
-			asset:  newAsset("aerospike-prometheus-exporter_1.17.0_x86_64.tgz", "", "linux", "amd64", false),
+			asset:  newAsset("aerospike-prometheus-exporter_1.17.0_x86_64.tgz", "", Platform{"linux", "amd64", libcGNU}),
 			weight: 4,
 		},
 		{
 			name:   "prometheus-iotdb-exporter_1.1_linux_64bit.tar.gz",
-			asset:  newAsset("prometheus-iotdb-exporter_1.1_linux_64bit.tar.gz", "", "linux", "amd64", false),
+			asset:  newAsset("prometheus-iotdb-exporter_1.1_linux_64bit.tar.gz", "", Platform{"linux", "amd64", libcGNU}),
 			weight: 6,
 		},
 		{
 			name:   "prometheus-exporter-linux-1.0.1.tgz",
-			asset:  newAsset("prometheus-exporter-linux-1.0.1.tgz", "", "linux", "amd64", false),
+			asset:  newAsset("prometheus-exporter-linux-1.0.1.tgz", "", Platform{"linux", "amd64", libcGNU}),
 			weight: 4,
 		},
 		{
 			name:   "couchdb-prometheus-exporter_30.10.1_Linux_x86_64.tar.gz",
-			asset:  newAsset("couchdb-prometheus-exporter_30.10.1_Linux_x86_64.tar.gz", "", "linux", "amd64", false),
+			asset:  newAsset("couchdb-prometheus-exporter_30.10.1_Linux_x86_64.tar.gz", "", Platform{"linux", "amd64", libcGNU}),
 			weight: 6,
 		},
 		{
 			name:   "prosafe_exporter-v0.2.8-x86_64-lnx.zip",
-			asset:  newAsset("prosafe_exporter-v0.2.8-x86_64-lnx.zip", "", "linux", "amd64", false),
+			asset:  newAsset("prosafe_exporter-v0.2.8-x86_64-lnx.zip", "", Platform{"linux", "amd64", libcGNU}),
 			weight: 6,
 		},
 		{
 			name:   "beanstalkd_exporter-1.0.5.linux-amd64.sha256",
-			asset:  newAsset("beanstalkd_exporter-1.0.5.linux-amd64.sha256", "", "linux", "amd64", false),
+			asset:  newAsset("beanstalkd_exporter-1.0.5.linux-amd64.sha256", "", Platform{"linux", "amd64", libcGNU}),
 			weight: 5,
 		},
 		{
 			name:   "site24x7_exporter-1.1.1-aarch64-unknown-linux-gnu",
-			asset:  newAsset("site24x7_exporter-1.1.1-aarch64-unknown-linux-gnu", "", "linux", "amd64", false),
+			asset:  newAsset("site24x7_exporter-1.1.1-aarch64-unknown-linux-gnu", "", Platform{"linux", "amd64", libcGNU}),
 			weight: 4,
 		},
 		{
 			name:   "php-fpm-exporter.linux.amd64.sha256.txt",
-			asset:  newAsset("php-fpm-exporter.linux.amd64.sha256.txt", "", "linux", "amd64", false),
+			asset:  newAsset("php-fpm-exporter.linux.amd64.sha256.txt", "", Platform{"linux", "amd64", libcGNU}),
 			weight: 5,
 		},
 		{
 			name:   "softether_exporter-v0.2.0-x86_64-lnx.zip",
-			asset:  newAsset("softether_exporter-v0.2.0-x86_64-lnx.zip", "", "linux", "amd64", false),
+			asset:  newAsset("softether_exporter-v0.2.0-x86_64-lnx.zip", "", Platform{"linux", "amd64", libcGNU}),
+			weight: 6,
+		},
+		// win is not matched in darwin
+		{
+			name:   "ripgrep-14.1.0-x86_64-apple-darwin.tar.gz",
+			asset:  newAsset("ripgrep-14.1.0-x86_64-apple-darwin.tar.gz", "", Platform{"windows", "amd64", ""}),
+			weight: 5,
+		},
+		{
+			name:   "ripgrep-14.1.0-x86_64-pc-windows-msvc.zip",
+			asset:  newAsset("ripgrep-14.1.0-x86_64-pc-windows-msvc.zip", "", Platform{"windows", "amd64", ""}),
+			weight: 6,
+		},
+		// arm is not matched in arm64
+		{
+			name:   "helm-v3.15.3-linux-arm64.tar.gz",
+			asset:  newAsset("helm-v3.15.3-linux-arm64.tar.gz", "", Platform{"linux", "arm", libcGNU}),
+			weight: 5,
+		},
+		// 386 is not matched in version
+		{
+			name:   "prometheus-2.386.1.linux-amd64.tar.gz",
+			asset:  newAsset("prometheus-2.386.1.linux-amd64.tar.gz", "", Platform{"linux", "386", libcGNU}),
+			weight: 5,
+		},
+		// x86 is not matched in x86_64
+		{
+			name:   "bat-v0.24.0-x86_64-unknown-linux-gnu.tar.gz",
+			asset:  newAsset("bat-v0.24.0-x86_64-unknown-linux-gnu.tar.gz", "", Platform{"linux", "386", libcGNU}),
+			weight: 5,
+		},
+		{
+			name:   "bat-v0.24.0-i686-unknown-linux-gnu.tar.gz",
+			asset:  newAsset("bat-v0.24.0-i686-unknown-linux-gnu.tar.gz", "", Platform{"linux", "386", libcGNU}),
+			weight: 6,
+		},
+		// js is not matched in json
+		{
+			name:   "json_exporter-0.6.0.tar.gz",
+			asset:  newAsset("json_exporter-0.6.0.tar.gz", "", Platform{"linux", "amd64", libcGNU}),
+			weight: 2,
+		},
+		{
+			name:   "gh_2.52.0_macOS_amd64.zip",
+			asset:  newAsset("gh_2.52.0_macOS_amd64.zip", "", Platform{"darwin", "amd64", ""}),
 			weight: 6,
 		},
 	}
@@ -82,31 +131,87 @@ func TestFindMaxWeightAsset(t *testing.T) {
 		{
 			name: "not musl",
 			assets: Assets{
-				*newAsset("monit-5.34.0-linux-x64.tar.gz", "", "linux", "amd64", false),
-				*newAsset("monit-5.34.0-linux-x64-musl.tar.gz", "", "linux", "amd64", false),
+				*newAsset("monit-5.34.0-linux-x64.tar.gz", "", Platform{"linux", "amd64", libcGNU}),
+				*newAsset("monit-5.34.0-linux-x64-musl.tar.gz", "", Platform{"linux", "amd64", libcGNU}),
 			},
-			want:    *newAsset("monit-5.34.0-linux-x64.tar.gz", "", "linux", "amd64", false),
+			want:    *newAsset("monit-5.34.0-linux-x64.tar.gz", "", Platform{"linux", "amd64", libcGNU}),
 			wantErr: nil,
 		},
 		{
 			name: "musl",
 			assets: Assets{
-				*newAsset("monit-5.34.0-linux-x64.tar.gz", "", "linux", "amd64", true),
-				*newAsset("monit-5.34.0-linux-x64-musl.tar.gz", "", "linux", "amd64", true),
+				*newAsset("monit-5.34.0-linux-x64.tar.gz", "", Platform{"linux", "amd64", libcMusl}),
+				*newAsset("monit-5.34.0-linux-x64-musl.tar.gz", "", Platform{"linux", "amd64", libcMusl}),
 			},
-			want:    *newAsset("monit-5.34.0-linux-x64-musl.tar.gz", "", "linux", "amd64", true),
+			want:    *newAsset("monit-5.34.0-linux-x64-musl.tar.gz", "", Platform{"linux", "amd64", libcMusl}),
 			wantErr: nil,
 		},
 		{
 			name: "single max weight asset",
 			assets: Assets{
-				*newAsset("node_exporter-1.8.2.linux-amd64.tar.gz", "", "linux", "amd64", false),
-				*newAsset("node_exporter-1.8.2.linux-arm64.tar.gz", "", "linux", "amd64", false),
-				*newAsset("node_exporter-1.8.2.darwin-amd64.tar.gz", "", "linux", "amd64", false),
+				*newAsset("node_exporter-1.8.2.linux-amd64.tar.gz", "", Platform{"linux", "amd64", libcGNU}),
+				*newAsset("node_exporter-1.8.2.linux-arm64.tar.gz", "", Platform{"linux", "amd64", libcGNU}),
+				*newAsset("node_exporter-1.8.2.darwin-amd64.tar.gz", "", Platform{"linux", "amd64", libcGNU}),
+			},
+			want:    *newAsset("node_exporter-1.8.2.linux-amd64.tar.gz", "", Platform{"linux", "amd64", libcGNU}),
+			wantErr: nil,
+		},
+		{
+			name: "windows and darwin",
+			assets: Assets{
+				*newAsset("ripgrep-14.1.0-x86_64-apple-darwin.tar.gz", "", Platform{"windows", "amd64", ""}),
+				*newAsset("ripgrep-14.1.0-x86_64-pc-windows-msvc.zip", "", Platform{"windows", "amd64", ""}),
 			},
-			want:    *newAsset("node_exporter-1.8.2.linux-amd64.tar.gz", "", "linux", "amd64", false),
+			want:    *newAsset("ripgrep-14.1.0-x86_64-pc-windows-msvc.zip", "", Platform{"windows", "amd64", ""}),
 			wantErr: nil,
 		},
+		{
+			name: "arm and arm64",
+			assets: Assets{
+				*newAsset("helm-v3.15.3-linux-arm64.tar.gz", "", Platform{"linux", "arm", libcGNU}),
+				*newAsset("helm-v3.15.3-linux-arm.tar.gz", "", Platform{"linux", "arm", libcGNU}),
+			},
+			want:    *newAsset("helm-v3.15.3-linux-arm.tar.gz", "", Platform{"linux", "arm", libcGNU}),
+			wantErr: nil,
+		},
+		{
+			name: "exact arm variant",
+			assets: Assets{
+				*newAsset("node_exporter-1.8.2.linux-arm64.tar.gz", "", Platform{"linux", "armv7", libcGNU}),
+				*newAsset("node_exporter-1.8.2.linux-armv5.tar.gz", "", Platform{"linux", "armv7", libcGNU}),
+				*newAsset("node_exporter-1.8.2.linux-armv7.tar.gz", "", Platform{"linux", "armv7", libcGNU}),
+				*newAsset("node_exporter-1.8.2.linux-arm.tar.gz", "", Platform{"linux", "armv7", libcGNU}),
+			},
+			want:    *newAsset("node_exporter-1.8.2.linux-armv7.tar.gz", "", Platform{"linux", "armv7", libcGNU}),
+			wantErr: nil,
+		},
+		{
+			name: "newer arm variant",
+			assets: Assets{
+				*newAsset("frp_0.59.0_linux_armv7.tar.gz", "", Platform{"linux", "armv6", libcGNU}),
+				*newAsset("frp_0.59.0_linux_arm.tar.gz", "", Platform{"linux", "armv6", libcGNU}),
+			},
+			want:    *newAsset("frp_0.59.0_linux_arm.tar.gz", "", Platform{"linux", "armv6", libcGNU}),
+			wantErr: nil,
+		},
+		{
+			name: "armhf on armv6",
+			assets: Assets{
+				*newAsset("frp_0.59.0_linux_arm64.tar.gz", "", Platform{"linux", "armv6", libcGNU}),
+				*newAsset("frp_0.59.0_linux_armhf.tar.gz", "", Platform{"linux", "armv6", libcGNU}),
+				*newAsset("frp_0.59.0_linux_arm.tar.gz", "", Platform{"linux", "armv6", libcGNU}),
+			},
+			want:    *newAsset("frp_0.59.0_linux_armhf.tar.gz", "", Platform{"linux", "armv6", libcGNU}),
+			wantErr: nil,
+		},
+		{
+			name: "only newer arm variant",
+			assets: Assets{
+				*newAsset("node_exporter-1.8.2.linux-armv7.tar.gz", "", Platform{"linux", "armv6", libcGNU}),
+			},
+			want:    Asset{},
+			wantErr: ErrNoAsset,
+		},
 		{
 			name:    "no assets",
 			assets:  Assets{},
@@ -128,3 +233,43 @@ func TestFindMaxWeightAsset(t *testing.T) {
 		})
 	}
 }
+
+func TestFindMaxWeightAssetOfTargetOS(t *testing.T) {
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		t.Skip("the target is the linux/amd64 host")
+	}
+	defer func(format string) { hostPackageFormat = format }(hostPackageFormat)
+	hostPackageFormat = packageDeb
+	p := Platform{"linux", "amd64", hostLibc()}
+
+	// the packages of linux win the archives of another OS, which are of the same weight
+	for _, names := range [][]string{
+		{"tool_1.0.0_amd64.deb", "tool-1.0.0.x86_64.rpm", "tool_1.0.0_darwin_amd64.tar.gz"},
+		{"tool_1.0.0_amd64.deb", "tool-1.0.0.x86_64.rpm", "tool_1.0.0_darwin_amd64.tar.gz", "tool_1.0.0_windows_amd64.zip"},
+	} {
+		var assets Assets
+		for _, name := range names {
+			assets = append(assets, *newAsset(name, "", p))
+		}
+		got, err := assets.FindMaxWeightAsset()
+		if err != nil || got.Name != "tool_1.0.0_amd64.deb" {
+			t.Errorf("FindMaxWeightAsset(%v) = %s, %v, want tool_1.0.0_amd64.deb", names, got.Name, err)
+		}
+	}
+}
+
+func TestGiteaTaggedRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v1/repos/owner/repo/releases/tags/release%2F1.0%231" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"tag_name": "release/1.0#1"}`)
+	}))
+	defer server.Close()
+
+	release, err := NewGitea(server.URL, "", "owner/repo").GetTaggedRelease("release/1.0#1")
+	if err != nil || release.TagName != "release/1.0#1" {
+		t.Errorf("GetTaggedRelease() = %v, %v, want release/1.0#1", release.TagName, err)
+	}
+}
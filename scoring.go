@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ScoringRule adjusts the weight of the assets matched by the regexp or the token, e.g.,
+// a rule of match `\.zip$` and weight -1 prefers tarballs to zip.
+type ScoringRule struct {
+	Match  string `yaml:"match"`
+	Token  string `yaml:"token"`
+	Weight int    `yaml:"weight"`
+
+	re *regexp.Regexp
+}
+
+// default rules penalize the assets which are rarely wanted, but still installable if nothing else matches
+var defaultScoringRules = []ScoringRule{
+	{Match: `(?i)(^|[-_.])(debug|dbg|dbgsym|symbols?)([-_.]|$)`, Weight: -5},
+	{Match: `(?i)(^|[-_.])(src|sources?)([-_.]|$)`, Weight: -5},
+	{Match: `(?i)\.(sbom|spdx|cdx)([-_.]|$)`, Weight: -5},
+}
+
+func (r *ScoringRule) compile() error {
+	switch {
+	case r.Match != "" && r.Token != "":
+		return fmt.Errorf("invalid rule: match and token are mutually exclusive")
+	case r.Match != "":
+		re, err := regexp.Compile(r.Match)
+		if err != nil {
+			return fmt.Errorf("invalid rule: %w", err)
+		}
+		r.re = re
+	case r.Token == "":
+		return fmt.Errorf("invalid rule: missing match or token")
+	}
+	return nil
+}
+
+func (r ScoringRule) matches(name string) bool {
+	if r.re != nil {
+		return r.re.MatchString(name)
+	}
+	return slices.Contains(tokenize(name), strings.ToLower(r.Token))
+}
+
+func (r ScoringRule) String() string {
+	match := r.Match
+	if match == "" {
+		match = "token " + r.Token
+	}
+	return fmt.Sprintf("%s=%+d", match, r.Weight)
+}
+
+// parseScoringRule parses the rule of -rule, i.e., REGEX=DELTA, e.g., '\.zip$=-1'.
+func parseScoringRule(s string) (ScoringRule, error) {
+	i := strings.LastIndex(s, "=")
+	if i <= 0 {
+		return ScoringRule{}, fmt.Errorf("invalid rule: %s, must be REGEX=DELTA", s)
+	}
+	weight, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return ScoringRule{}, fmt.Errorf("invalid rule: %s, must be REGEX=DELTA", s)
+	}
+
+	r := ScoringRule{Match: s[:i], Weight: weight}
+	if err := r.compile(); err != nil {
+		return ScoringRule{}, err
+	}
+	return r, nil
+}
+
+// scoringRules returns the default rules, the rules of the config and -rule in order.
+func (o installOptions) scoringRules() ([]ScoringRule, error) {
+	config, err := LoadConfig(o.Config)
+	if err != nil {
+		return nil, err
+	}
+	return mergeScoringRules(config, o.Rules)
+}
+
+func mergeScoringRules(config *Config, flags []string) ([]ScoringRule, error) {
+	rules := slices.Clone(defaultScoringRules)
+	if config != nil {
+		rules = append(rules, config.Rules...)
+	}
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range flags {
+		r, err := parseScoringRule(s)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
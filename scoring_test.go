@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseScoringRule(t *testing.T) {
+	tests := []struct {
+		rule    string
+		match   string
+		weight  int
+		wantErr bool
+	}{
+		{`\.zip$=-1`, `\.zip$`, -1, false},
+		{`static=+2`, `static`, 2, false},
+		{`a=b=3`, `a=b`, 3, false},
+		{`static`, "", 0, true},
+		{`=1`, "", 0, true},
+		{`static=high`, "", 0, true},
+		{`[=1`, "", 0, true},
+	}
+
+	for _, tt := range tests {
+		r, err := parseScoringRule(tt.rule)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseScoringRule(%s) error = %v, wantErr %v", tt.rule, err, tt.wantErr)
+			continue
+		}
+		if r.Match != tt.match || r.Weight != tt.weight || (!tt.wantErr && r.re == nil) {
+			t.Errorf("parseScoringRule(%s) = %v, want %s=%+d", tt.rule, r, tt.match, tt.weight)
+		}
+	}
+}
+
+func TestScoringRules(t *testing.T) {
+	p := Platform{"linux", "amd64", libcGNU}
+	tests := []struct {
+		name    string
+		config  *Config
+		flags   []string
+		assets  []string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "debug",
+			assets: []string{"tool-linux-amd64-debug.tar.gz", "tool-linux-amd64.tar.gz"},
+			want:   "tool-linux-amd64.tar.gz",
+		},
+		{
+			name:   "symbols and source",
+			assets: []string{"tool-linux-amd64", "tool-linux-amd64.dbgsym", "tool-1.0.0-src.tar.gz"},
+			want:   "tool-linux-amd64",
+		},
+		{
+			name:   "sbom",
+			assets: []string{"tool_linux_amd64", "tool_linux_amd64.sbom"},
+			want:   "tool_linux_amd64",
+		},
+		{
+			name:    "tie",
+			assets:  []string{"tool-linux-amd64.zip", "tool-linux-amd64.tar.gz"},
+			wantErr: ErrMultipleMaxWeightAsset,
+		},
+		{
+			name:   "flag",
+			flags:  []string{`\.zip$=-1`},
+			assets: []string{"tool-linux-amd64.zip", "tool-linux-amd64.tar.gz"},
+			want:   "tool-linux-amd64.tar.gz",
+		},
+		{
+			name:   "config token",
+			config: &Config{Rules: []ScoringRule{{Token: "static", Weight: 1}}},
+			assets: []string{"tool-linux-amd64-static.tar.gz", "tool-linux-amd64.tar.gz"},
+			want:   "tool-linux-amd64-static.tar.gz",
+		},
+		{
+			name:   "flag after config",
+			config: &Config{Rules: []ScoringRule{{Token: "static", Weight: 1}}},
+			flags:  []string{`gnu=+2`},
+			assets: []string{"tool-linux-amd64-static.tar.gz", "tool-linux-amd64-gnu.tar.gz"},
+			want:   "tool-linux-amd64-gnu.tar.gz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := mergeScoringRules(tt.config, tt.flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			release := Release{ScoringRules: rules}
+			for _, name := range tt.assets {
+				release.Assets = append(release.Assets, Asset{Name: name})
+			}
+
+			got, reason, err := selectAsset(release, p)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("selectAsset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Name != tt.want {
+				t.Errorf("selectAsset() = %s (%s), want %s", got.Name, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultipleMaxWeightOrder(t *testing.T) {
+	p := Platform{"linux", "amd64", libcGNU}
+	var errs []string
+	for _, names := range [][]string{
+		{"tool-linux-amd64.zip", "tool-linux-amd64.tar.gz"},
+		{"tool-linux-amd64.tar.gz", "tool-linux-amd64.zip"},
+	} {
+		var assets Assets
+		for _, name := range names {
+			assets = append(assets, *newAsset(name, "", p))
+		}
+		_, err := assets.FindMaxWeightAsset()
+		errs = append(errs, err.Error())
+	}
+	if errs[0] != errs[1] || !strings.Contains(errs[0], "tool-linux-amd64.tar.gz: 6, tool-linux-amd64.zip: 6") {
+		t.Errorf("FindMaxWeightAsset() errors = %q, want the same order by name", errs)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("rules:\n  - match: '\\.zip$'\n    weight: -1\n  - token: static\n    weight: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []ScoringRule{{Match: `\.zip$`, Weight: -1}, {Token: "static", Weight: 1}}; len(c.Rules) != 2 || c.Rules[0].String() != want[0].String() || c.Rules[1].String() != want[1].String() {
+		t.Errorf("LoadConfig() rules = %v, want %v", c.Rules, want)
+	}
+
+	// the default config is optional, but the given one is not
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if c, err := LoadConfig(""); err != nil || len(c.Rules) != 0 {
+		t.Errorf("LoadConfig() of missing default = %v, %v", c, err)
+	}
+	if _, err := LoadConfig(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("LoadConfig() of missing file, want error")
+	}
+
+	if _, err := mergeScoringRules(&Config{Rules: []ScoringRule{{Weight: 1}}}, nil); err == nil {
+		t.Error("mergeScoringRules() of rule without match or token, want error")
+	}
+}
+
+func TestLoadConfigPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `cosign:
+  key: /etc/cosign.pub
+  trusted_root: ~/.config/release-installer/trusted_root.json
+gpg:
+  keyring: ~/.config/release-installer/pubring.gpg
+minisign:
+  keys:
+    owner/repo: ~/minisign.pub
+    owner/other: RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Cosign.Key != "/etc/cosign.pub" || c.Cosign.TrustedRoot != filepath.Join(home, ".config/release-installer/trusted_root.json") || c.GPG.Keyring != filepath.Join(home, ".config/release-installer/pubring.gpg") {
+		t.Errorf("LoadConfig() = %+v, want the paths expanded", c)
+	}
+	if keys := c.Minisign.Keys; keys["owner/repo"] != filepath.Join(home, "minisign.pub") || keys["owner/other"] != "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3" {
+		t.Errorf("LoadConfig() minisign keys = %v", keys)
+	}
+}
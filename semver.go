@@ -0,0 +1,153 @@
+package main
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// lenient SemVer 2.0, the v prefix and missing minor or patch are allowed
+	semverRe = regexp.MustCompile(`^[vV]?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`)
+	// date-based versions, e.g., 2024-07-01, which would be 2024.0.0 with the pre-release 07-01 in SemVer
+	dateVersionRe = regexp.MustCompile(`^[vV]?\d{4}-\d{2}-\d{2}(?:$|[^0-9])`)
+	// anything starting with a number, e.g., 5.34.0b, 2024-07-01
+	versionNameRe   = regexp.MustCompile(`^v?\d+([.\-_+]?[0-9a-z]+)*$`)
+	versionTokensRe = regexp.MustCompile(`\d+|[a-z]+`)
+)
+
+// words marking a pre-release in non-SemVer versions, e.g., 1.0b1, 2.0rc1
+var preReleaseWords = []string{"a", "alpha", "b", "beta", "dev", "pre", "preview", "rc"}
+
+type semVersion struct {
+	nums [3]int
+	pre  []string
+}
+
+func parseSemver(v string) (semVersion, bool) {
+	if dateVersionRe.MatchString(v) {
+		return semVersion{}, false
+	}
+	m := semverRe.FindStringSubmatch(v)
+	if m == nil {
+		return semVersion{}, false
+	}
+
+	var sv semVersion
+	for i := 0; i < 3; i++ {
+		if m[i+1] != "" {
+			sv.nums[i], _ = strconv.Atoi(m[i+1])
+		}
+	}
+	if m[4] != "" {
+		sv.pre = strings.Split(m[4], ".")
+	}
+	// build metadata is ignored in precedence
+	return sv, true
+}
+
+func (v semVersion) compare(other semVersion) int {
+	for i := 0; i < 3; i++ {
+		if n := cmp.Compare(v.nums[i], other.nums[i]); n != 0 {
+			return n
+		}
+	}
+
+	// a pre-release version has lower precedence than the normal version
+	switch {
+	case len(v.pre) == 0 && len(other.pre) == 0:
+		return 0
+	case len(v.pre) == 0:
+		return 1
+	case len(other.pre) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.pre) && i < len(other.pre); i++ {
+		if n := comparePreReleaseIdentifier(v.pre[i], other.pre[i]); n != 0 {
+			return n
+		}
+	}
+	return cmp.Compare(len(v.pre), len(other.pre))
+}
+
+// comparePreReleaseIdentifier compares numeric identifiers numerically, and others lexically in ASCII order,
+// e.g., RC < beta, numeric identifiers have lower precedence than alphanumeric ones.
+func comparePreReleaseIdentifier(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return cmp.Compare(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// compareLenient compares versions which are not SemVer, e.g., 5.34.0b or 2024.07.01,
+// by runs of digits and letters, a trailing pre-release word makes the version lower.
+func compareLenient(v1, v2 string) int {
+	t1 := versionTokensRe.FindAllString(strings.ToLower(v1), -1)
+	t2 := versionTokensRe.FindAllString(strings.ToLower(v2), -1)
+
+	for i := 0; i < len(t1) || i < len(t2); i++ {
+		switch {
+		case i >= len(t1):
+			return -lenientSuffixSign(t2[i])
+		case i >= len(t2):
+			return lenientSuffixSign(t1[i])
+		}
+
+		n1, err1 := strconv.Atoi(t1[i])
+		n2, err2 := strconv.Atoi(t2[i])
+		var n int
+		switch {
+		case err1 == nil && err2 == nil:
+			n = cmp.Compare(n1, n2)
+		case err1 == nil:
+			// 1.0.1 > 1.0rc1
+			n = 1
+		case err2 == nil:
+			n = -1
+		default:
+			n = strings.Compare(t1[i], t2[i])
+		}
+		if n != 0 {
+			return n
+		}
+	}
+
+	return 0
+}
+
+// lenientSuffixSign returns the sign of a version with the extra token against the one without it.
+func lenientSuffixSign(token string) int {
+	if slices.Contains(preReleaseWords, token) {
+		return -1
+	}
+	return 1
+}
+
+// isVersion reports whether s looks like a version rather than an arbitrary name.
+func isVersion(s string) bool {
+	return versionNameRe.MatchString(strings.ToLower(s))
+}
+
+// isPreRelease reports whether the version is a pre-release, e.g., 1.0.0-rc.1 or 1.0b1.
+func isPreRelease(v string) bool {
+	if sv, ok := parseSemver(v); ok {
+		return len(sv.pre) > 0
+	}
+	for _, token := range versionTokensRe.FindAllString(strings.ToLower(v), -1) {
+		if slices.Contains(preReleaseWords, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrProvenanceMismatch = errors.New("provenance mismatch")
+	// the time of signing of a bare DSSE envelope is unknown offline, e.g., multiple.intoto.jsonl of slsa-github-generator
+	ErrTlogEntryRequired = fmt.Errorf("%w: tlog entry required, a bare DSSE envelope is not supported but a Sigstore bundle", ErrInvalidSignature)
+)
+
+const (
+	inTotoPayloadType = "application/vnd.in-toto+json"
+	slsaProvenanceV02 = "https://slsa.dev/provenance/v0.2"
+	slsaProvenanceV1  = "https://slsa.dev/provenance/v1"
+)
+
+var (
+	// attestations of the release, which may cover many assets, e.g., multiple.intoto.jsonl
+	slsaAttestationSuffixes = []string{".intoto.jsonl", ".intoto.sigstore", ".sigstore.json"}
+	// source repository extension of Fulcio certificates
+	oidSourceRepositoryURI = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 12}
+)
+
+// Provenance is the verified SLSA provenance of the asset.
+type Provenance struct {
+	PredicateType string `json:"predicate_type"`
+	BuilderID     string `json:"builder_id"`
+	SourceURI     string `json:"source_uri"`
+	// SHA-256 digest of the subject, i.e., the verified asset
+	Digest string `json:"digest"`
+}
+
+// slsaVerifier verifies the SLSA provenance of the asset offline, i.e., an in-toto statement in a DSSE envelope signed by
+// a Fulcio certificate of the trusted root, which must be built by the builder from the source repo.
+type slsaVerifier struct {
+	root *trustedRoot
+	// builder ID without the version, e.g., https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml
+	builderID string
+	// normalized source repo, e.g., github.com/owner/repo
+	source string
+	// identity and OIDC issuer of the signing certificate
+	identity *regexp.Regexp
+	issuer   string
+}
+
+// dsseEnvelope is the DSSE envelope of an attestation, the signature signs the PAE of the payload.
+type dsseEnvelope struct {
+	PayloadType string `json:"payloadType"`
+	Payload     []byte `json:"payload"`
+	Signatures  []struct {
+		Sig []byte `json:"sig"`
+	} `json:"signatures"`
+}
+
+// attestation is the DSSE envelope, with the signing certificate and the tlog entry of the Sigstore bundle.
+type attestation struct {
+	envelope *dsseEnvelope
+	cert     *x509.Certificate
+	chain    []*x509.Certificate
+	tlog     *tlogEntry
+}
+
+// inTotoStatement is the payload of the attestation, the predicate is the provenance of the subjects.
+type inTotoStatement struct {
+	Subject       []inTotoSubject `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+func (o installOptions) slsaVerifier(config *Config) (*slsaVerifier, error) {
+	rootPath := cmp.Or(o.TrustedRoot, config.Cosign.TrustedRoot)
+	if rootPath == "" {
+		return nil, errors.New("slsa verification requires -trusted-root")
+	}
+	root, err := loadTrustedRoot(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &slsaVerifier{
+		root:      root,
+		builderID: cmp.Or(o.SLSABuilderID, config.SLSA.BuilderID),
+		source:    normalizeSourceURI(cmp.Or(o.SLSASourceURI, o.sourceURI())),
+		issuer:    o.CertIssuer,
+	}
+	if v.source == "" {
+		return nil, errors.New("slsa verification requires -slsa-source-uri")
+	}
+	if o.CertIdentity != "" {
+		if v.identity, err = regexp.Compile(o.CertIdentity); err != nil {
+			return nil, fmt.Errorf("invalid certificate identity: %w", err)
+		}
+	}
+	return v, nil
+}
+
+// sourceURI returns the repo of the git providers, e.g., github.com/owner/repo.
+func (o installOptions) sourceURI() string {
+	if !slices.Contains([]string{"github", "gitlab", "gitea"}, o.Provider) {
+		return ""
+	}
+	host := "github.com"
+	if o.URL != "" {
+		u, err := url.Parse(o.URL)
+		if err != nil {
+			return ""
+		}
+		host = u.Host
+	}
+	return host + "/" + o.Repo
+}
+
+func (v *slsaVerifier) name() string {
+	return verifySLSA
+}
+
+// verify verifies the provenance of the target in the attestation assets, the subjects are matched by the digest.
+func (v *slsaVerifier) verify(release Release, target Asset, path, destDir string) (*Verification, error) {
+	digest, err := fileDigest(path, crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	var unsupported error
+	for _, a := range release.Assets {
+		if !slices.ContainsFunc(slsaAttestationSuffixes, func(suffix string) bool { return strings.HasSuffix(a.Name, suffix) }) {
+			continue
+		}
+		data, err := downloadSignature(release, a, destDir)
+		if err != nil {
+			return nil, err
+		}
+		attestations, err := parseAttestations(data)
+		if errors.Is(err, ErrTlogEntryRequired) {
+			// the provenance may be in another attestation asset
+			unsupported = fmt.Errorf("%s: %w", a.Name, err)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", a.Name, err)
+		}
+
+		for _, att := range attestations {
+			if att.envelope.PayloadType != inTotoPayloadType {
+				continue
+			}
+			var s inTotoStatement
+			if err := json.Unmarshal(att.envelope.Payload, &s); err != nil {
+				return nil, fmt.Errorf("%s: invalid in-toto statement: %w", a.Name, err)
+			}
+			if !s.hasSubject(digest) || (s.PredicateType != slsaProvenanceV02 && s.PredicateType != slsaProvenanceV1) {
+				continue
+			}
+
+			signer, err := v.verifyAttestation(att)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", a.Name, err)
+			}
+			p, err := s.provenance()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", a.Name, err)
+			}
+			p.Digest = hex.EncodeToString(digest)
+			if err := v.checkProvenance(p); err != nil {
+				return nil, fmt.Errorf("%s: %w", a.Name, err)
+			}
+			log.Printf("%s is built by %s from %s", target.Name, p.BuilderID, p.SourceURI)
+			return &Verification{Signature: a.Name, Signer: signer, Provenance: p}, nil
+		}
+	}
+	return nil, unsupported
+}
+
+// verifyAttestation verifies the envelope is signed by the certificate trusted at the time of the tlog entry,
+// and returns the identity of the certificate.
+func (v *slsaVerifier) verifyAttestation(att attestation) (string, error) {
+	e := att.envelope
+	if len(e.Signatures) == 0 {
+		return "", fmt.Errorf("%w: no signature of the envelope", ErrInvalidSignature)
+	}
+	cert, chain := att.cert, att.chain
+	if cert == nil {
+		return "", fmt.Errorf("%w: no certificate of the envelope", ErrInvalidSignature)
+	}
+
+	// the certificate is short-lived, and trusted at the time the attestation is logged
+	if att.tlog == nil {
+		return "", fmt.Errorf("%w: no tlog entry of the attestation, a Sigstore bundle is required", ErrInvalidSignature)
+	}
+	if err := v.root.verifyTlogEntry(att.tlog); err != nil {
+		return "", err
+	}
+	if err := att.tlog.checkPayload(e.Payload); err != nil {
+		return "", err
+	}
+	if err := v.root.verifyCertificate(cert, chain, time.Unix(att.tlog.integratedTime, 0)); err != nil {
+		return "", err
+	}
+
+	// anyone can get a certificate from Fulcio, it must be of the source repo or the identity
+	if source := certificateExtension(cert, oidSourceRepositoryURI); source != "" {
+		if normalizeSourceURI(source) != v.source {
+			return "", fmt.Errorf("%w: certificate of source repository %s, expected %s", ErrInvalidSignature, source, v.source)
+		}
+	} else if v.identity == nil {
+		return "", fmt.Errorf("%w: certificate without source repository, -certificate-identity-regexp is required", ErrInvalidSignature)
+	}
+	if err := checkCertificateIdentity(cert, v.identity, v.issuer); err != nil {
+		return "", err
+	}
+
+	pae := dssePAE(e.PayloadType, e.Payload)
+	for _, sig := range e.Signatures {
+		if verifyBlob(cert.PublicKey, bytes.NewReader(pae), sig.Sig) == nil {
+			return strings.Join(certificateIdentities(cert), ", "), nil
+		}
+	}
+	return "", ErrInvalidSignature
+}
+
+// checkProvenance checks the provenance is built by the builder of any version, and from the source repo.
+func (v *slsaVerifier) checkProvenance(p *Provenance) error {
+	if v.builderID != "" && p.BuilderID != v.builderID && !strings.HasPrefix(p.BuilderID, v.builderID+"@") {
+		return fmt.Errorf("%w: built by %s, expected %s", ErrProvenanceMismatch, p.BuilderID, v.builderID)
+	}
+	if normalizeSourceURI(p.SourceURI) != v.source {
+		return fmt.Errorf("%w: built from %s, expected %s", ErrProvenanceMismatch, p.SourceURI, v.source)
+	}
+	return nil
+}
+
+func (s *inTotoStatement) hasSubject(digest []byte) bool {
+	return slices.ContainsFunc(s.Subject, func(subject inTotoSubject) bool {
+		return strings.EqualFold(subject.Digest["sha256"], hex.EncodeToString(digest))
+	})
+}
+
+// provenance returns the builder and the source of the SLSA provenance v0.2 or v1.
+func (s *inTotoStatement) provenance() (*Provenance, error) {
+	p := &Provenance{PredicateType: s.PredicateType}
+	switch s.PredicateType {
+	case slsaProvenanceV02:
+		var predicate struct {
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+			Invocation struct {
+				ConfigSource struct {
+					URI string `json:"uri"`
+				} `json:"configSource"`
+			} `json:"invocation"`
+		}
+		if err := json.Unmarshal(s.Predicate, &predicate); err != nil {
+			return nil, fmt.Errorf("invalid provenance: %w", err)
+		}
+		p.BuilderID, p.SourceURI = predicate.Builder.ID, predicate.Invocation.ConfigSource.URI
+	case slsaProvenanceV1:
+		var predicate struct {
+			BuildDefinition struct {
+				ExternalParameters struct {
+					Workflow struct {
+						Repository string `json:"repository"`
+					} `json:"workflow"`
+				} `json:"externalParameters"`
+				ResolvedDependencies []struct {
+					URI string `json:"uri"`
+				} `json:"resolvedDependencies"`
+			} `json:"buildDefinition"`
+			RunDetails struct {
+				Builder struct {
+					ID string `json:"id"`
+				} `json:"builder"`
+			} `json:"runDetails"`
+		}
+		if err := json.Unmarshal(s.Predicate, &predicate); err != nil {
+			return nil, fmt.Errorf("invalid provenance: %w", err)
+		}
+		p.BuilderID = predicate.RunDetails.Builder.ID
+		// the workflow repository of GitHub Actions, or the source of the first dependency
+		p.SourceURI = predicate.BuildDefinition.ExternalParameters.Workflow.Repository
+		if deps := predicate.BuildDefinition.ResolvedDependencies; p.SourceURI == "" && len(deps) > 0 {
+			p.SourceURI = deps[0].URI
+		}
+	}
+	return p, nil
+}
+
+// checkPayload checks the dsse or intoto entry is of the payload of the envelope.
+func (e *tlogEntry) checkPayload(payload []byte) error {
+	type hash struct {
+		Algorithm string `json:"algorithm"`
+		Value     string `json:"value"`
+	}
+	var body struct {
+		Kind string `json:"kind"`
+		Spec struct {
+			PayloadHash *hash `json:"payloadHash"`
+			Content     struct {
+				PayloadHash *hash `json:"payloadHash"`
+			} `json:"content"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(e.body, &body); err != nil {
+		return fmt.Errorf("invalid tlog entry: %w", err)
+	}
+
+	var h *hash
+	switch body.Kind {
+	case "dsse":
+		h = body.Spec.PayloadHash
+	case "intoto":
+		h = body.Spec.Content.PayloadHash
+	default:
+		return fmt.Errorf("unsupported tlog entry: %s", body.Kind)
+	}
+	digest := sha256.Sum256(payload)
+	if h == nil || h.Algorithm != "sha256" || h.Value != hex.EncodeToString(digest[:]) {
+		return fmt.Errorf("%w: tlog entry of another payload", ErrInvalidSignature)
+	}
+	return nil
+}
+
+// parseAttestations parses the Sigstore bundles of DSSE envelopes, one per line of JSON Lines,
+// the bundles of message signatures are skipped, and bare DSSE envelopes are rejected.
+func parseAttestations(data []byte) ([]attestation, error) {
+	var attestations []attestation
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid attestation: %w", err)
+		}
+
+		var probe struct {
+			MediaType string `json:"mediaType"`
+		}
+		if err := json.Unmarshal(raw, &probe); err != nil {
+			return nil, fmt.Errorf("invalid attestation: %w", err)
+		}
+		if !strings.HasPrefix(probe.MediaType, "application/vnd.dev.sigstore.bundle") {
+			return nil, ErrTlogEntryRequired
+		}
+
+		var b sigstoreBundle
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return nil, fmt.Errorf("invalid bundle: %w", err)
+		}
+		if b.DSSEEnvelope == nil {
+			continue
+		}
+		att := attestation{envelope: b.DSSEEnvelope}
+		var err error
+		if att.cert, att.chain, att.tlog, err = b.material(); err != nil {
+			return nil, err
+		}
+		attestations = append(attestations, att)
+	}
+	return attestations, nil
+}
+
+// dssePAE returns the pre-authentication encoding of the payload, which is signed instead of the payload.
+func dssePAE(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+// normalizeSourceURI returns the repo of the source URI, e.g., github.com/owner/repo of git+https://github.com/owner/repo@refs/tags/v1.0.0.
+func normalizeSourceURI(s string) string {
+	s = strings.TrimPrefix(s, "git+")
+	if _, after, ok := strings.Cut(s, "://"); ok {
+		s = after
+	}
+	s, _, _ = strings.Cut(s, "@")
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(s, "/"), ".git"))
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+const (
+	slsaGeneratorID = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml"
+	githubIssuer    = "https://token.actions.githubusercontent.com"
+)
+
+// provenance returns the in-toto statement of the SLSA provenance of the content built from the source repo.
+func provenance(content, predicateType, builderID, source string) []byte {
+	digest := sha256.Sum256([]byte(content))
+	var predicate map[string]any
+	if predicateType == slsaProvenanceV02 {
+		predicate = map[string]any{
+			"builder":    map[string]any{"id": builderID},
+			"invocation": map[string]any{"configSource": map[string]any{"uri": "git+" + source + "@refs/tags/v1.0.0"}},
+		}
+	} else {
+		predicate = map[string]any{
+			"buildDefinition": map[string]any{
+				"externalParameters": map[string]any{"workflow": map[string]any{"repository": source, "ref": "refs/tags/v1.0.0"}},
+			},
+			"runDetails": map[string]any{"builder": map[string]any{"id": builderID}},
+		}
+	}
+	data, _ := json.Marshal(map[string]any{
+		"_type":         "https://in-toto.io/Statement/v1",
+		"subject":       []any{map[string]any{"name": "tool", "digest": map[string]any{"sha256": hex.EncodeToString(digest[:])}}},
+		"predicateType": predicateType,
+		"predicate":     predicate,
+	})
+	return data
+}
+
+// attest signs the statement by a certificate of the builder and the source repo, and returns the DSSE envelope
+// with the PEM certificate of slsa-github-generator, or the Sigstore bundle with the tlog entry.
+func (f *sigstoreFixture) attest(t *testing.T, statement []byte, sourceRepo string, bundle bool) map[string]any {
+	t.Helper()
+	signedAt := time.Now().AddDate(0, 0, -1)
+	sourceExt, err := asn1.MarshalWithParams(sourceRepo, "utf8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, cert := f.certificate(t, slsaGeneratorID+"@refs/tags/v2.0.0", githubIssuer, signedAt, pkix.Extension{Id: oidSourceRepositoryURI, Value: sourceExt})
+
+	digest := sha256.Sum256(dssePAE(inTotoPayloadType, statement))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bundle {
+		return map[string]any{
+			"payloadType": inTotoPayloadType,
+			"payload":     statement,
+			"signatures":  []any{map[string]any{"keyid": "", "sig": sig, "cert": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}))}},
+		}
+	}
+
+	payloadDigest := sha256.Sum256(statement)
+	body, err := json.Marshal(map[string]any{
+		"apiVersion": "0.0.1",
+		"kind":       "dsse",
+		"spec": map[string]any{
+			"payloadHash": map[string]any{"algorithm": "sha256", "value": hex.EncodeToString(payloadDigest[:])},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]any{
+		"mediaType": "application/vnd.dev.sigstore.bundle.v0.3+json",
+		"verificationMaterial": map[string]any{
+			"certificate": map[string]any{"rawBytes": cert},
+			"tlogEntries": []any{f.logEntry(t, "dsse", body, signedAt)},
+		},
+		"dsseEnvelope": map[string]any{
+			"payloadType": inTotoPayloadType,
+			"payload":     statement,
+			"signatures":  []any{map[string]any{"keyid": "", "sig": sig}},
+		},
+	}
+}
+
+func TestSLSAVerification(t *testing.T) {
+	f, other := newSigstoreFixture(t), newSigstoreFixture(t)
+	const source = "https://github.com/owner/repo"
+
+	jsonl := func(values ...map[string]any) []byte {
+		var lines []byte
+		for _, v := range values {
+			data, err := json.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines = append(append(lines, data...), '\n')
+		}
+		return lines
+	}
+
+	v02 := provenance("artifact", slsaProvenanceV02, slsaGeneratorID+"@refs/tags/v2.0.0", source)
+	tests := []struct {
+		name        string
+		attestation string
+		data        func() []byte
+		builderID   string
+		wantErr     error
+	}{
+		{"intoto.jsonl of bundles", "multiple.intoto.jsonl", func() []byte { return jsonl(f.attest(t, v02, source, true)) }, slsaGeneratorID, nil},
+		{
+			"bundle",
+			"tool_linux_amd64.tar.gz.intoto.sigstore",
+			func() []byte {
+				return jsonl(f.attest(t, provenance("artifact", slsaProvenanceV1, "https://github.com/actions/runner/github-hosted", source), source, true))
+			},
+			"",
+			nil,
+		},
+		{
+			"checksum file",
+			"multiple.intoto.jsonl",
+			func() []byte {
+				return jsonl(f.attest(t, provenance("checksums", slsaProvenanceV02, slsaGeneratorID, source), source, true))
+			},
+			slsaGeneratorID,
+			nil,
+		},
+		{
+			"another subject first",
+			"multiple.intoto.jsonl",
+			func() []byte {
+				return jsonl(f.attest(t, provenance("another", slsaProvenanceV02, slsaGeneratorID, source), source, true), f.attest(t, v02, source, true))
+			},
+			slsaGeneratorID,
+			nil,
+		},
+		{"another builder", "multiple.intoto.jsonl", func() []byte { return jsonl(f.attest(t, v02, source, true)) }, "https://github.com/owner/builder", ErrProvenanceMismatch},
+		{
+			"another source",
+			"multiple.intoto.jsonl",
+			func() []byte {
+				return jsonl(f.attest(t, provenance("artifact", slsaProvenanceV02, slsaGeneratorID, "https://github.com/owner/fork"), source, true))
+			},
+			"",
+			ErrProvenanceMismatch,
+		},
+		{
+			"certificate of another repo",
+			"multiple.intoto.jsonl",
+			func() []byte { return jsonl(f.attest(t, v02, "https://github.com/owner/fork", true)) },
+			"",
+			ErrInvalidSignature,
+		},
+		{
+			"tampered",
+			"multiple.intoto.jsonl",
+			func() []byte {
+				e := f.attest(t, v02, source, true)
+				e["dsseEnvelope"].(map[string]any)["payload"] = provenance("artifact", slsaProvenanceV02, "https://github.com/owner/builder", source)
+				return jsonl(e)
+			},
+			"",
+			ErrInvalidSignature,
+		},
+		{"untrusted", "multiple.intoto.jsonl", func() []byte { return jsonl(other.attest(t, v02, source, true)) }, "", ErrInvalidSignature},
+		{
+			"no subject",
+			"multiple.intoto.jsonl",
+			func() []byte {
+				return jsonl(f.attest(t, provenance("another", slsaProvenanceV02, slsaGeneratorID, source), source, true))
+			},
+			"",
+			ErrNoSignature,
+		},
+		// the time of signing is unknown without the tlog entry
+		{"no tlog", "multiple.intoto.jsonl", func() []byte { return jsonl(f.attest(t, v02, source, false)) }, "", ErrTlogEntryRequired},
+		{"none", "", nil, "", ErrNoSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := installOptions{packageOptions: packageOptions{Provider: "github", Repo: "owner/repo", TrustedRoot: f.rootPath, SLSABuilderID: tt.builderID}}
+			v, err := o.slsaVerifier(&Config{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s := newAssetServer(t)
+			targets := []Asset{s.write("tool_linux_amd64.tar.gz", "artifact"), s.write("checksums.txt", "checksums")}
+			assets := slices.Clone(targets)
+			if tt.data != nil {
+				assets = append(assets, s.write(tt.attestation, string(tt.data())))
+			}
+			verifications, err := s.verify(v, assets, targets...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("verifyAsset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(verifications) != 1 || verifications[0].Provenance == nil {
+				t.Fatalf("verifyAsset() = %+v, want the provenance", verifications)
+			}
+			if got := verifications[0]; got.Method != verifySLSA || got.Signature != tt.attestation || normalizeSourceURI(got.Provenance.SourceURI) != "github.com/owner/repo" {
+				t.Errorf("verifyAsset() = %+v, provenance %+v", got, got.Provenance)
+			}
+		})
+	}
+}
+
+func TestNormalizeSourceURI(t *testing.T) {
+	tests := map[string]string{
+		"git+https://github.com/Owner/Repo@refs/tags/v1.0.0": "github.com/owner/repo",
+		"https://github.com/owner/repo":                      "github.com/owner/repo",
+		"https://gitlab.com/group/project.git":               "gitlab.com/group/project",
+		"github.com/owner/repo/":                             "github.com/owner/repo",
+	}
+	for uri, want := range tests {
+		if got := normalizeSourceURI(uri); got != want {
+			t.Errorf("normalizeSourceURI(%s) = %s, want %s", uri, got, want)
+		}
+	}
+}
+
+func TestSLSAVerifier(t *testing.T) {
+	f := newSigstoreFixture(t)
+
+	tests := []struct {
+		name       string
+		o          installOptions
+		wantSource string
+		wantErr    bool
+	}{
+		{"github", installOptions{packageOptions: packageOptions{Provider: "github", Repo: "owner/repo", TrustedRoot: f.rootPath}}, "github.com/owner/repo", false},
+		{"gitea", installOptions{packageOptions: packageOptions{Provider: "gitea", URL: "https://codeberg.org", Repo: "owner/repo", TrustedRoot: f.rootPath}}, "codeberg.org/owner/repo", false},
+		{"source", installOptions{packageOptions: packageOptions{Provider: "apache", Repo: "tool", TrustedRoot: f.rootPath, SLSASourceURI: "https://github.com/apache/tool"}}, "github.com/apache/tool", false},
+		{"no source", installOptions{packageOptions: packageOptions{Provider: "apache", Repo: "tool", TrustedRoot: f.rootPath}}, "", true},
+		{"no trusted root", installOptions{packageOptions: packageOptions{Provider: "github", Repo: "owner/repo"}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := tt.o.slsaVerifier(&Config{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("slsaVerifier() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && v.source != tt.wantSource {
+				t.Errorf("slsaVerifier() source = %s, want %s", v.source, tt.wantSource)
+			}
+		})
+	}
+
+	// -slsa-builder-id implies -verify slsa, the trusted root is of the config
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, "release-installer"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "release-installer", configFileName), []byte("cosign:\n  trusted_root: "+f.rootPath+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	verifiers, err := installOptions{packageOptions: packageOptions{Provider: "github", Repo: "owner/repo", SLSABuilderID: slsaGeneratorID}}.verifiers()
+	if err != nil || len(verifiers) != 1 || verifiers[0].name() != verifySLSA {
+		t.Errorf("verifiers() = %v, %v, want slsa", verifiers, err)
+	}
+}
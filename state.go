@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const stateFileName = "state.json"
+
+// InstallRecord is what an install left behind.
+type InstallRecord struct {
+	packageOptions
+	Tag         string    `json:"tag"`
+	Prerelease  bool      `json:"prerelease"`
+	Asset       string    `json:"asset"`
+	DownloadURL string    `json:"download_url"`
+	SHA256      string    `json:"sha256"`
+	Files       []string  `json:"files"`
+	InstalledAt time.Time `json:"installed_at"`
+
+	// verified signatures and provenance of the asset
+	Verifications []Verification `json:"verifications,omitempty"`
+}
+
+// options returns the options to install the latest release again,
+// the token falls back to the recorded environment variable.
+func (r InstallRecord) options(token string) installOptions {
+	if token == "" && r.TokenEnv != "" {
+		token = os.Getenv(r.TokenEnv)
+	}
+	return installOptions{packageOptions: r.packageOptions, Token: token}
+}
+
+// same reports whether both records are the installs of the same package.
+func (r InstallRecord) same(other InstallRecord) bool {
+	return r.Provider == other.Provider && r.URL == other.URL && r.Repo == other.Repo && r.Dir == other.Dir
+}
+
+type State struct {
+	path     string
+	Installs []InstallRecord `json:"installs"`
+}
+
+// stateDir returns $XDG_STATE_HOME/release-installer, defaults to ~/.local/state/release-installer.
+func stateDir() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "release-installer"), nil
+}
+
+func LoadState() (*State, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &State{path: filepath.Join(dir, stateFileName)}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *State) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tempFile, err := os.CreateTemp(dir, ".state.*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+
+	if _, err := tempFile.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), s.path)
+}
+
+// Add adds the record, replacing the previous install of the same package.
+func (s *State) Add(r InstallRecord) {
+	i := slices.IndexFunc(s.Installs, r.same)
+	if i == -1 {
+		s.Installs = append(s.Installs, r)
+	} else {
+		s.Installs[i] = r
+	}
+}
+
+// Find returns the records of the repo, installed in dir if it is not empty.
+func (s *State) Find(repo, dir string) []InstallRecord {
+	var records []InstallRecord
+	for _, r := range s.Installs {
+		if r.Repo == repo && (dir == "" || r.Dir == dir) {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+func (s *State) Remove(r InstallRecord) {
+	s.Installs = slices.DeleteFunc(s.Installs, r.same)
+}
+
+// previousInstall returns the recorded install of the same package.
+func previousInstall(r InstallRecord) (InstallRecord, bool) {
+	s, err := LoadState()
+	if err != nil {
+		return InstallRecord{}, false
+	}
+	i := slices.IndexFunc(s.Installs, r.same)
+	if i == -1 {
+		return InstallRecord{}, false
+	}
+	return s.Installs[i], true
+}
+
+// removeOrphanFiles removes the previously installed files which are not installed again, e.g., dropped by the new release.
+func removeOrphanFiles(previous, files []string) {
+	for _, f := range previous {
+		if slices.Contains(files, f) {
+			continue
+		}
+		if err := os.Remove(f); err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				log.Printf("Error removing %s: %v", f, err)
+			}
+			continue
+		}
+		log.Printf("Removed %s, which is not installed again", f)
+	}
+}
+
+func recordInstall(r InstallRecord) error {
+	s, err := LoadState()
+	if err != nil {
+		return err
+	}
+	s.Add(r)
+	return s.Save()
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoveOrphanFiles(t *testing.T) {
+	dir := t.TempDir()
+	kept, dropped := filepath.Join(dir, "tool"), filepath.Join(dir, "tool-helper")
+	for _, f := range []string{kept, dropped} {
+		if err := os.WriteFile(f, nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the missing file is already removed
+	removeOrphanFiles([]string{kept, dropped, filepath.Join(dir, "missing")}, []string{kept})
+	if _, err := os.Stat(kept); err != nil {
+		t.Errorf("%s is removed: %v", kept, err)
+	}
+	if _, err := os.Stat(dropped); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("%s is not removed: %v", dropped, err)
+	}
+}
+
+func TestInstallRecordOptions(t *testing.T) {
+	t.Setenv("TEST_TOKEN", "secret")
+	r := InstallRecord{packageOptions: packageOptions{Provider: "gitea", Repo: "owner/tool", Constraint: "^1.0", AllowPrerelease: true, AllowDraft: true, TokenEnv: "TEST_TOKEN"}, Tag: "v1.0.0"}
+
+	o := r.options("")
+	if o.Tag != "" || o.Constraint != "^1.0" || !o.AllowPrerelease || !o.AllowDraft || o.Token != "secret" {
+		t.Errorf("options() = %+v", o)
+	}
+}
+
+func TestInstallRecordJSON(t *testing.T) {
+	data := `{"provider": "github", "repo": "owner/tool", "tag": "v1.0.0-rc.1", "prerelease": true, "dir": "/usr/local/bin", "constraint": "^1.0", "allow_prerelease": true, "verify": "cosign"}`
+	var r InstallRecord
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Repo != "owner/tool" || r.Tag != "v1.0.0-rc.1" || !r.Prerelease || r.Constraint != "^1.0" || !r.AllowPrerelease || r.Verify != "cosign" {
+		t.Errorf("json.Unmarshal() = %+v", r)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// assetTemplateData is the data of -asset-template, e.g., {{.Name}}_{{.Version}}_{{.OS}}_{{.Arch}}.tar.gz.
+type assetTemplateData struct {
+	// repo base name
+	Name string
+	// tag without the prefix before the number, e.g., 1.2.3 of v1.2.3 or jq-1.2.3
+	Version string
+	Tag     string
+	// target platform after the aliases, e.g., x86_64 for amd64 with the alias amd64=x86_64
+	OS   string
+	Arch string
+	Libc string
+}
+
+func parseAssetTemplate(s string) (*template.Template, error) {
+	t, err := template.New("asset").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset template: %w", err)
+	}
+	return t, nil
+}
+
+// renderAssetTemplate renders the asset name of the release for the platform, the platform values are replaced by the aliases.
+func renderAssetTemplate(t *template.Template, name string, release Release, p Platform, aliases map[string]string) (string, error) {
+	alias := func(s string) string {
+		if a, ok := aliases[s]; ok {
+			return a
+		}
+		return s
+	}
+
+	data := assetTemplateData{
+		Name:    name,
+		Version: trimVersion(release.TagName),
+		Tag:     release.TagName,
+		OS:      alias(p.OS),
+		Arch:    alias(p.Arch),
+		Libc:    alias(p.Libc),
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid asset template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// trimVersion trims the prefix of the tag before the number, or returns the tag if no number.
+func trimVersion(tag string) string {
+	if i := strings.IndexFunc(tag, unicode.IsDigit); i > 0 {
+		return tag[i:]
+	}
+	return tag
+}
+
+// parseAlias parses the alias of -alias, i.e., VALUE=ALIAS, e.g., amd64=x86_64.
+func parseAlias(s string) (string, string, error) {
+	value, alias, ok := strings.Cut(s, "=")
+	if !ok || value == "" || alias == "" {
+		return "", "", fmt.Errorf("invalid alias: %s, must be VALUE=ALIAS", s)
+	}
+	return value, alias, nil
+}
+
+// templateAliases returns the aliases of the config, overridden by -alias.
+func (o installOptions) templateAliases() (map[string]string, error) {
+	config, err := LoadConfig(o.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := maps.Clone(config.Aliases)
+	if aliases == nil {
+		aliases = map[string]string{}
+	}
+	for _, s := range o.Aliases {
+		value, alias, err := parseAlias(s)
+		if err != nil {
+			return nil, err
+		}
+		aliases[value] = alias
+	}
+	return aliases, nil
+}
+
+// assetTemplate parses -asset-template, and returns the aliases, the template is nil if not given.
+func (o installOptions) assetTemplate() (*template.Template, map[string]string, error) {
+	if o.AssetTemplate == "" {
+		return nil, nil, nil
+	}
+	if o.Pattern != "" {
+		return nil, nil, errors.New("pattern and asset template are mutually exclusive")
+	}
+
+	t, err := parseAssetTemplate(o.AssetTemplate)
+	if err != nil {
+		return nil, nil, err
+	}
+	aliases, err := o.templateAliases()
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, aliases, nil
+}
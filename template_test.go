@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrimVersion(t *testing.T) {
+	tests := map[string]string{
+		"v1.2.3":     "1.2.3",
+		"1.2.3":      "1.2.3",
+		"jq-1.7.1":   "1.7.1",
+		"cli/v2.0.0": "2.0.0",
+		"nightly":    "nightly",
+	}
+
+	for tag, want := range tests {
+		if got := trimVersion(tag); got != want {
+			t.Errorf("trimVersion(%s) = %s, want %s", tag, got, want)
+		}
+	}
+}
+
+func TestRenderAssetTemplate(t *testing.T) {
+	release := Release{TagName: "v1.2.3"}
+	tests := []struct {
+		tmpl    string
+		p       Platform
+		aliases map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"{{.Name}}_{{.Version}}_{{.OS}}_{{.Arch}}.tar.gz", Platform{"linux", "amd64", libcGNU}, nil, "tool_1.2.3_linux_amd64.tar.gz", false},
+		{"{{.Name}}-{{.Tag}}-{{.Arch}}-unknown-{{.OS}}-{{.Libc}}.tar.gz", Platform{"linux", "amd64", libcMusl}, map[string]string{"amd64": "x86_64"}, "tool-v1.2.3-x86_64-unknown-linux-musl.tar.gz", false},
+		{"{{.Name}}_{{.OS}}_{{.Arch}}.zip", Platform{"darwin", "arm64", ""}, map[string]string{"darwin": "macOS"}, "tool_macOS_arm64.zip", false},
+		{`{{.Name}}_{{if eq .OS "windows"}}win.zip{{else}}{{.OS}}.tar.gz{{end}}`, Platform{"windows", "amd64", ""}, nil, "tool_win.zip", false},
+		{"{{.Name}", Platform{"linux", "amd64", libcGNU}, nil, "", true},
+		{"{{.Platform}}", Platform{"linux", "amd64", libcGNU}, nil, "", true},
+	}
+
+	for _, tt := range tests {
+		tmpl, err := parseAssetTemplate(tt.tmpl)
+		var got string
+		if err == nil {
+			got, err = renderAssetTemplate(tmpl, "tool", release, tt.p, tt.aliases)
+		}
+		if (err != nil) != tt.wantErr {
+			t.Errorf("renderAssetTemplate(%s) error = %v, wantErr %v", tt.tmpl, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("renderAssetTemplate(%s) = %s, want %s", tt.tmpl, got, tt.want)
+		}
+	}
+}
+
+func TestSelectAssetByName(t *testing.T) {
+	p := Platform{"linux", "amd64", libcGNU}
+	release := Release{
+		Assets: []Asset{
+			{Name: "tool_1.2.3_linux_x86_64.tar.gz"},
+			{Name: "tool_1.2.3_linux_x86_64.tar.gz.sbom"},
+			{Name: "tool_1.2.3_linux_arm64.tar.gz"},
+		},
+		AssetName: "tool_1.2.3_linux_x86_64.tar.gz",
+	}
+
+	got, _, err := selectAsset(release, p)
+	if err != nil || got.Name != release.AssetName {
+		t.Errorf("selectAsset() = %s, %v, want %s", got.Name, err, release.AssetName)
+	}
+
+	release.AssetName = "tool_1.2.3_linux_amd64.tar.gz"
+	if _, _, err := selectAsset(release, p); !errors.Is(err, ErrNoAsset) {
+		t.Errorf("selectAsset() error = %v, want %v", err, ErrNoAsset)
+	}
+}
+
+func TestTemplateAliases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("aliases:\n  amd64: x86_64\n  darwin: macOS\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	o := installOptions{packageOptions: packageOptions{Config: path, Aliases: []string{"darwin=Darwin", "arm64=aarch64"}}}
+	aliases, err := o.templateAliases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"amd64": "x86_64", "darwin": "Darwin", "arm64": "aarch64"}
+	if len(aliases) != len(want) {
+		t.Errorf("templateAliases() = %v, want %v", aliases, want)
+	}
+	for k, v := range want {
+		if aliases[k] != v {
+			t.Errorf("templateAliases()[%s] = %s, want %s", k, aliases[k], v)
+		}
+	}
+
+	o = installOptions{packageOptions: packageOptions{Config: path, Aliases: []string{"amd64"}}}
+	if _, err := o.templateAliases(); err == nil {
+		t.Error("templateAliases() of invalid alias, want error")
+	}
+
+	o = installOptions{packageOptions: packageOptions{Pattern: "amd64", AssetTemplate: "{{.Name}}"}}
+	if _, _, err := o.assetTemplate(); err == nil {
+		t.Error("assetTemplate() with pattern, want error")
+	}
+}
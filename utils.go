@@ -1,11 +1,9 @@
 package main
 
 import (
-	"archive/tar"
-	"archive/zip"
-	"compress/gzip"
 	"crypto/sha256"
 	"fmt"
+	"hash"
 	"io"
 	"log"
 	"net/http"
@@ -13,16 +11,11 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 )
 
-var supportedArchiveFormat = []string{
-	".tar.gz",
-	".tgz",
-	".zip",
-}
-
 func isNumeric(s string) bool {
 	_, err := strconv.Atoi(s)
 	return err == nil
@@ -37,15 +30,23 @@ func urlEncode(s string) string {
 	return url.PathEscape(s)
 }
 
-func extractAndInstallExecutables(archivePath, destDir string, excludeRe *regexp.Regexp) error {
-	install := func(name string, r io.Reader, mode os.FileMode) error {
+// extractAndInstallExecutables installs the executables in the archive of the sniffed content and compression
+// built for the target, and returns their paths, an identical file already in destDir is returned only if it is owned,
+// i.e., installed by the previous install. Nothing is installed unless every executable is valid.
+func extractAndInstallExecutables(archivePath, content, compression, destDir string, excludeRe *regexp.Regexp, p Platform, owned []string) ([]string, error) {
+	extractDir := filepath.Join(filepath.Dir(archivePath), "extracted")
+	if err := os.MkdirAll(extractDir, 0755); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	extract := func(name string, r io.Reader, mode os.FileMode) error {
 		if excludeRe != nil && excludeRe.MatchString(name) {
 			return nil
 		}
 
-		oldpath := filepath.Join(filepath.Dir(archivePath), filepath.Base(name))
-		newpath := filepath.Join(destDir, filepath.Base(name))
-		outFile, err := os.Create(oldpath)
+		path := filepath.Join(extractDir, filepath.Base(name))
+		outFile, err := os.Create(path)
 		if err != nil {
 			return err
 		}
@@ -58,131 +59,136 @@ func extractAndInstallExecutables(archivePath, destDir string, excludeRe *regexp
 			return err
 		}
 
+		if err := validateBinary(path, p); err != nil {
+			return err
+		}
+		names = append(names, name)
+		return nil
+	}
+
+	if err := walkArchive(archivePath, content, compression, extract); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, name := range names {
+		oldpath := filepath.Join(extractDir, filepath.Base(name))
+		newpath := filepath.Join(destDir, filepath.Base(name))
 		isSameFile, err := isIdenticalFile(oldpath, newpath)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if isSameFile {
 			log.Printf("%s is identical, no need to install", newpath)
-			return nil
+			if slices.Contains(owned, newpath) {
+				files = append(files, newpath)
+			}
+			continue
 		}
 
 		// os.Rename() may cause "invalid cross-device link" error
 		if err := moveFile(oldpath, newpath); err != nil {
-			return err
+			return nil, err
 		}
 
+		files = append(files, newpath)
 		log.Printf("Installed %s to %s", name, destDir)
-
-		return nil
 	}
 
-	lowerSrc := strings.ToLower(archivePath)
-	if strings.HasSuffix(lowerSrc, ".tar.gz") || strings.HasSuffix(lowerSrc, ".tgz") {
-		// gzip tar
-		file, err := os.Open(archivePath)
-		if err != nil {
-			return err
-		}
-		defer file.Close()
+	return files, nil
+}
 
-		gzr, err := gzip.NewReader(file)
-		if err != nil {
-			return err
+// selectAsset returns the asset of the release for the platform, which is matched by the pattern or of the max weight,
+// and the reason why it is selected.
+func selectAsset(release Release, p Platform) (Asset, string, error) {
+	var assets Assets
+	for _, asset := range release.Assets {
+		if !isIgnoredFile(asset.Name) {
+			a := newAsset(asset.Name, asset.URL, p)
+			a.applyRules(release.ScoringRules)
+			assets = append(assets, *a)
 		}
-		defer gzr.Close()
-
-		tr := tar.NewReader(gzr)
-
-		for {
-			header, err := tr.Next()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				return err
-			}
+	}
 
-			// Check if it is regulare executable file
-			if header.Typeflag == tar.TypeReg && header.Mode&0111 != 0 {
-				if err := install(header.Name, tr, os.FileMode(header.Mode)); err != nil {
-					return err
-				}
+	if release.AssetName != "" {
+		for _, asset := range assets {
+			if asset.Name == release.AssetName {
+				return asset, fmt.Sprintf("the asset named by template %s", release.AssetName), nil
 			}
 		}
-	} else if strings.HasSuffix(lowerSrc, ".zip") {
-		// zip
-		r, err := zip.OpenReader(archivePath)
-		if err != nil {
-			return err
-		}
-		defer r.Close()
-
-		for _, file := range r.File {
-			if fh := file.FileHeader; !fh.FileInfo().IsDir() && fh.Mode()&0111 != 0 {
-				rc, err := file.Open()
-				if err != nil {
-					return err
-				}
-				defer rc.Close()
-
-				if err := install(fh.Name, rc, os.FileMode(fh.Mode())); err != nil {
-					return err
-				}
-			}
+		return Asset{}, "", fmt.Errorf("%w named %s", ErrNoAsset, release.AssetName)
+	}
+
+	if release.AssetPattern == nil {
+		return assets.findMaxWeightAsset()
+	}
+
+	// match by pattern
+	var matchedAssets Assets
+	for _, asset := range assets {
+		if release.AssetPattern.MatchString(asset.Name) {
+			matchedAssets = append(matchedAssets, asset)
 		}
-	} else {
-		return fmt.Errorf("Unsupported archive file: %s", filepath.Base(archivePath))
 	}
 
-	return nil
+	switch len(matchedAssets) {
+	case 0:
+		return Asset{}, "", fmt.Errorf("No matched asset by pattern in assets")
+	case 1:
+		return matchedAssets[0], fmt.Sprintf("the only asset matched by pattern %s", release.AssetPattern), nil
+	default:
+		return Asset{}, "", fmt.Errorf("Multiple matched assets found by pattern in assets: %s", matchedAssets.JoinName())
+	}
 }
 
-func downloadReleaseAsset(release Release, destDir string) (string, error) {
-	var (
-		maxWeightAsset Asset
-		assets         Assets
-		err            error
-	)
-	for _, asset := range release.Assets {
-		if !isIgnoredFile(asset.Name) {
-			assets = append(assets, asset)
-		}
+// downloadReleaseAsset downloads the matched asset of the release for the platform to destDir, and returns the asset, its path
+// and the verifications of the signatures.
+func downloadReleaseAsset(release Release, p Platform, destDir string) (Asset, string, []Verification, error) {
+	maxWeightAsset, _, err := selectAsset(release, p)
+	if err != nil {
+		return Asset{}, "", nil, err
 	}
 
-	if release.AssetPattern == nil {
-		maxWeightAsset, err = assets.FindMaxWeightAsset()
-		if err != nil {
-			return "", err
-		}
-	} else {
-		// match by pattern
-		var matchedAssets Assets
-		for _, asset := range assets {
-			if release.AssetPattern.MatchString(asset.Name) {
-				matchedAssets = append(matchedAssets, asset)
-			}
-		}
+	checksum, checksumAsset, found, err := fetchChecksum(release, maxWeightAsset, destDir)
+	if err != nil {
+		return Asset{}, "", nil, fmt.Errorf("failed to fetch checksum: %w", err)
+	}
+
+	var h hash.Hash
+	if found {
+		h = checksum.NewHash()
+	}
+	destPath := filepath.Join(destDir, maxWeightAsset.Name)
+	if err := download(maxWeightAsset.URL, destPath, release.AuthHeaders, h); err != nil {
+		return Asset{}, "", nil, err
+	}
 
-		switch len(matchedAssets) {
-		case 0:
-			return "", fmt.Errorf("No matched asset by pattern in assets")
-		case 1:
-			maxWeightAsset = matchedAssets[0]
-		default:
-			return "", fmt.Errorf("Multiple matched assets found by pattern in assets: %s", matchedAssets.JoinName())
+	if found {
+		if err := checksum.Verify(h); err != nil {
+			return Asset{}, "", nil, fmt.Errorf("%s: %w", maxWeightAsset.Name, err)
 		}
+		log.Printf("Verified %s checksum of %s", checksum.Algo, maxWeightAsset.Name)
+	} else {
+		log.Printf("No checksum found for %s, skipping verification", maxWeightAsset.Name)
 	}
 
-	destPath := filepath.Join(destDir, maxWeightAsset.Name)
-	if err := download(maxWeightAsset.URL, destPath, release.AuthHeaders); err != nil {
-		return "", err
+	var verifications []Verification
+	if len(release.Verifiers) > 0 {
+		// the signed checksum file verifies the asset too
+		targets := []verifyTarget{{maxWeightAsset, destPath}}
+		if found {
+			targets = append(targets, verifyTarget{checksumAsset, filepath.Join(destDir, checksumAsset.Name)})
+		}
+		if verifications, err = verifyAsset(release, targets, destDir); err != nil {
+			return Asset{}, "", nil, err
+		}
 	}
 
-	return destPath, nil
+	return maxWeightAsset, destPath, verifications, nil
 }
 
-func download(url, destPath string, headers map[string]string) error {
+// download saves the url to destPath, the content is also written to h if it is not nil.
+func download(url, destPath string, headers map[string]string, h hash.Hash) error {
 	filename := filepath.Base(destPath)
 	log.Printf("Downloading %s from %s", filename, url)
 	resp, err := httpGet(url, headers)
@@ -201,7 +207,11 @@ func download(url, destPath string, headers map[string]string) error {
 	}
 	defer file.Close()
 
-	_, err = io.Copy(file, resp.Body)
+	var w io.Writer = file
+	if h != nil {
+		w = io.MultiWriter(file, h)
+	}
+	_, err = io.Copy(w, resp.Body)
 	if err != nil {
 		return err
 	}
@@ -247,41 +257,19 @@ func addExecutePermission(fpath string) error {
 	return nil
 }
 
-func isSupportedArchiveFormat(name string) bool {
-	name = strings.ToLower(name)
-	for _, suffix := range supportedArchiveFormat {
-		if strings.HasSuffix(name, suffix) {
-			return true
-		}
-	}
-	return false
-}
-
+// compareVersions compares versions by SemVer 2.0 precedence, versions which are not SemVer,
+// e.g., 5.34.0b or 2024.07.01, are compared leniently.
 func compareVersions(v1, v2 string) int {
-	v1 = strings.TrimPrefix(strings.ToLower(v1), "v")
-	v2 = strings.TrimPrefix(strings.ToLower(v2), "v")
-	parts1 := strings.Split(v1, ".")
-	parts2 := strings.Split(v2, ".")
-
-	for i := 0; i < len(parts1) || i < len(parts2); i++ {
-		var num1, num2 int
-
-		if i < len(parts1) {
-			num1, _ = strconv.Atoi(parts1[i])
-		}
-
-		if i < len(parts2) {
-			num2, _ = strconv.Atoi(parts2[i])
-		}
-
-		if num1 < num2 {
-			return -1 // less
-		} else if num1 > num2 {
-			return 1 // greater
-		}
+	// pre-release identifiers are case-sensitive in SemVer
+	v1 = strings.TrimLeft(v1, "vV")
+	v2 = strings.TrimLeft(v2, "vV")
+
+	sv1, ok1 := parseSemver(v1)
+	sv2, ok2 := parseSemver(v2)
+	if ok1 && ok2 {
+		return sv1.compare(sv2)
 	}
-
-	return 0 // equal
+	return compareLenient(v1, v2)
 }
 
 func boolToInt(b bool) int {
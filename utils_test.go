@@ -1,6 +1,13 @@
 package main
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"debug/elf"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -13,6 +20,24 @@ func TestCompareVersions(t *testing.T) {
 		{"1.0.0", "1.0.0", 0},
 		{"5.30.0", "5.9", 1},
 		{"5.9", "5.12.2", -1},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.10.0-rc1", "1.10.0", -1},
+		{"1.10.0", "1.10.0-rc1", 1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0+build.1", "1.0.0+build.2", 0},
+		{"5.34.0b", "5.34.0", -1},
+		{"5.34.0", "5.34.1b", -1},
+		{"1.1.1w", "1.1.1", 1},
+		{"2024.07.01", "2024.10.01", -1},
+		{"1.2.3.4", "1.2.3", 1},
+		{"1.0.0-RC.1", "1.0.0-beta.1", -1},
+		{"V1.0.0", "1.0.0", 0},
+		{"2024-07-01", "2024-10-01", -1},
+		{"2024-07-01", "2024-07-01-rc1", 1},
 	}
 
 	for _, test := range tests {
@@ -22,3 +47,129 @@ func TestCompareVersions(t *testing.T) {
 		}
 	}
 }
+
+func TestIsPreRelease(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{"v1.2.3", false},
+		{"1.2.3-rc.1", true},
+		{"5.34.0b", true},
+		{"2.0rc1", true},
+		{"2024.07.01", false},
+		{"2024-07-01", false},
+		{"2024-07-01-rc1", true},
+	}
+
+	for _, test := range tests {
+		if result := isPreRelease(test.version); result != test.expected {
+			t.Errorf("isPreRelease(%s) = %v; expected %v", test.version, result, test.expected)
+		}
+	}
+}
+
+func TestIsVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"5.34.0", true},
+		{"v1.2.3-rc.1", true},
+		{"2024-07-01", true},
+		{"latest", false},
+		{"monit-5.34.0", false},
+	}
+
+	for _, test := range tests {
+		if result := isVersion(test.name); result != test.expected {
+			t.Errorf("isVersion(%s) = %v; expected %v", test.name, result, test.expected)
+		}
+	}
+}
+
+func TestExtractAndInstallOwnedFiles(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "example.tar.gz")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := gzip.NewWriter(f)
+	writeTestTar(t, w)
+	w.Close()
+	f.Close()
+
+	tests := []struct {
+		name     string
+		existing bool
+		owned    bool
+		want     bool
+	}{
+		{"new", false, false, true},
+		{"identical and owned", true, true, true},
+		// the identical file is not installed by the package, and must not be uninstalled
+		{"identical but not owned", true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destDir := t.TempDir()
+			dest := filepath.Join(destDir, "example")
+			if tt.existing {
+				if err := os.WriteFile(dest, []byte("content of example/example"), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var owned []string
+			if tt.owned {
+				owned = []string{dest}
+			}
+
+			files, err := extractAndInstallExecutables(archive, contentTar, compressionGzip, destDir, nil, hostPlatform(), owned)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := slices.Contains(files, dest); got != tt.want {
+				t.Errorf("extractAndInstallExecutables() = %v, want %s recorded %v", files, dest, tt.want)
+			}
+			if _, err := os.Stat(dest); err != nil {
+				t.Errorf("%s is not installed: %v", dest, err)
+			}
+		})
+	}
+}
+
+func TestExtractAndInstallInvalidExecutable(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "example.tar")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(f)
+	// the valid script precedes the binary of another arch
+	for _, file := range []struct {
+		name string
+		body []byte
+	}{
+		{"example/example", []byte("#!/bin/sh\n")},
+		{"example/example-helper", testELF(elf.EM_AARCH64, elf.ELFDATA2LSB, "")},
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: file.name, Mode: 0755, Size: int64(len(file.body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(file.body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	f.Close()
+
+	destDir := t.TempDir()
+	_, err = extractAndInstallExecutables(archive, contentTar, compressionNone, destDir, nil, Platform{"linux", "amd64", libcGNU}, nil)
+	if !errors.Is(err, ErrIncompatibleBinary) {
+		t.Fatalf("extractAndInstallExecutables() error = %v, want %v", err, ErrIncompatibleBinary)
+	}
+	if entries, _ := os.ReadDir(destDir); len(entries) > 0 {
+		t.Errorf("%s is installed before the invalid executable", entries[0].Name())
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrNoSignature      = errors.New("no signature found")
+)
+
+const (
+	verifyCosign   = "cosign"
+	verifyGPG      = "gpg"
+	verifyMinisign = "minisign"
+	verifySLSA     = "slsa"
+)
+
+var knownVerifiers = []string{verifyCosign, verifyGPG, verifyMinisign, verifySLSA}
+
+// verifier verifies the downloaded asset by its signature assets in the release.
+type verifier interface {
+	// name of -verify, e.g., cosign
+	name() string
+	// verify verifies the file of the target asset, and returns nil if the target has no signature.
+	verify(release Release, target Asset, path, destDir string) (*Verification, error)
+}
+
+// Verification is a verified signature recorded in the install.
+type Verification struct {
+	// name of the verifier, e.g., cosign
+	Method string `json:"method"`
+	// verified asset, i.e., the asset or its checksum file, and the signature asset
+	Asset     string `json:"asset"`
+	Signature string `json:"signature"`
+	// fingerprint or key ID of the signing key, or identity of the signing certificate
+	Signer string `json:"signer,omitempty"`
+	// SLSA provenance of the asset
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+// verifyTarget is a downloaded asset, i.e., the selected asset, or its checksum file.
+type verifyTarget struct {
+	asset Asset
+	path  string
+}
+
+// verifyAsset verifies the asset by every verifier, the checksum file verifying the asset is verified instead
+// if the asset has no signature, e.g., checksums.txt.sig.
+func verifyAsset(release Release, targets []verifyTarget, destDir string) ([]Verification, error) {
+	var verifications []Verification
+	for _, v := range release.Verifiers {
+		verified := false
+		for _, t := range targets {
+			result, err := v.verify(release, t.asset, t.path, destDir)
+			if err != nil {
+				return nil, fmt.Errorf("%s verification of %s: %w", v.name(), t.asset.Name, err)
+			}
+			if result != nil {
+				log.Printf("Verified %s signature of %s", v.name(), t.asset.Name)
+				result.Method, result.Asset = v.name(), t.asset.Name
+				verifications = append(verifications, *result)
+				verified = true
+				break
+			}
+		}
+		if !verified {
+			names := make([]string, len(targets))
+			for i, t := range targets {
+				names[i] = t.asset.Name
+			}
+			return nil, fmt.Errorf("%s verification: %w for %s", v.name(), ErrNoSignature, strings.Join(names, " or "))
+		}
+	}
+	return verifications, nil
+}
+
+// findSignatureAsset returns the asset of the target name with one of the suffixes, e.g., foo.tar.gz.sig.
+func findSignatureAsset(assets []Asset, target string, suffixes ...string) (Asset, bool) {
+	for _, suffix := range suffixes {
+		for _, a := range assets {
+			if a.Name == target+suffix {
+				return a, true
+			}
+		}
+	}
+	return Asset{}, false
+}
+
+// downloadSignature downloads the signature asset to destDir, and returns its content.
+func downloadSignature(release Release, a Asset, destDir string) ([]byte, error) {
+	destPath := filepath.Join(destDir, a.Name)
+	if err := download(a.URL, destPath, release.AuthHeaders, nil); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(destPath)
+}
+
+// verifiers returns the verifiers of -verify, which is a comma separated list, e.g., cosign,gpg,
+// gpg is implied by -gpg-keyring, minisign by -minisign-key, and slsa by -slsa-builder-id or -slsa-source-uri.
+func (o installOptions) verifiers() ([]verifier, error) {
+	names := o.Verify
+	if o.GPGKeyring != "" {
+		names += "," + verifyGPG
+	}
+	if o.MinisignKey != "" {
+		names += "," + verifyMinisign
+	}
+	if o.SLSABuilderID != "" || o.SLSASourceURI != "" {
+		names += "," + verifySLSA
+	}
+	names = strings.Trim(names, ",")
+	if names == "" {
+		return nil, nil
+	}
+
+	config, err := LoadConfig(o.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	var verifiers []verifier
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		// each verification once
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		switch name {
+		case verifyCosign:
+			v, err := o.cosignVerifier(config)
+			if err != nil {
+				return nil, err
+			}
+			verifiers = append(verifiers, v)
+		case verifyGPG:
+			v, err := o.gpgVerifier(config)
+			if err != nil {
+				return nil, err
+			}
+			verifiers = append(verifiers, v)
+		case verifyMinisign:
+			v, err := o.minisignVerifier(config)
+			if err != nil {
+				return nil, err
+			}
+			verifiers = append(verifiers, v)
+		case verifySLSA:
+			v, err := o.slsaVerifier(config)
+			if err != nil {
+				return nil, err
+			}
+			verifiers = append(verifiers, v)
+		default:
+			return nil, fmt.Errorf("unsupported verification: %s, options: %s", name, strings.Join(knownVerifiers, ", "))
+		}
+	}
+	return verifiers, nil
+}
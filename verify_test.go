@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// assetServer serves the release assets written to its directory.
+type assetServer struct {
+	t   *testing.T
+	dir string
+	url string
+}
+
+// newAssetServer starts the server of a new directory, which is closed at the end of the test.
+func newAssetServer(t *testing.T) *assetServer {
+	t.Helper()
+	dir := t.TempDir()
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	t.Cleanup(server.Close)
+	return &assetServer{t: t, dir: dir, url: server.URL}
+}
+
+// write writes the content of the asset, and returns the asset.
+func (s *assetServer) write(name, content string) Asset {
+	s.t.Helper()
+	if err := os.WriteFile(filepath.Join(s.dir, name), []byte(content), 0644); err != nil {
+		s.t.Fatal(err)
+	}
+	return Asset{Name: name, URL: s.url + "/" + name}
+}
+
+// verify verifies the targets of the release assets by the verifier, the targets are already downloaded.
+func (s *assetServer) verify(v verifier, assets []Asset, targets ...Asset) ([]Verification, error) {
+	var ts []verifyTarget
+	for _, a := range targets {
+		ts = append(ts, verifyTarget{a, filepath.Join(s.dir, a.Name)})
+	}
+	return verifyAsset(Release{Assets: assets, Verifiers: []verifier{v}}, ts, s.t.TempDir())
+}